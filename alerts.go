@@ -0,0 +1,252 @@
+// alerts.go - Threshold alerts surfaced in the footer and the alert log
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// alertLogPath is where fired alerts are appended, one per line
+var alertLogPath = filepath.Join("logs", "alerts.log")
+
+// Severity ranks alerts; only critical ones trigger desktop notifications
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	if s == SeverityCritical {
+		return "CRITICAL"
+	}
+	return "WARNING"
+}
+
+// Alert is a fired alert
+type Alert struct {
+	Time     time.Time
+	Severity Severity
+	Message  string
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("%s %s %s", a.Time.Format(time.RFC3339), a.Severity, a.Message)
+}
+
+// Levels at which a resource becomes critical, and the lower levels at
+// which -format nagios already warns
+const (
+	criticalDiskPercent    = 95 // used space on a filesystem
+	criticalMemoryPressure = 90 // see MemoryInfo.Pressure
+	warnDiskPercent        = 85
+	warnMemoryPressure     = 80
+)
+
+// Level is a metric with the values at which it warns and turns critical
+type Level struct {
+	Name  string
+	Value float64
+	Warn  float64
+	Crit  float64
+}
+
+// Critical reports whether the value has reached the critical level
+func (l Level) Critical() bool {
+	return l.Value >= l.Crit
+}
+
+// Severity judges the value; over is false while it is below both levels
+func (l Level) Severity() (severity Severity, over bool) {
+	switch {
+	case l.Critical():
+		return SeverityCritical, true
+	case l.Value >= l.Warn:
+		return SeverityWarning, true
+	}
+	return SeverityWarning, false
+}
+
+func memoryLevel(stats *internal.SystemStats) Level {
+	return Level{Name: "mem_pressure", Value: stats.Memory.Pressure, Warn: warnMemoryPressure, Crit: criticalMemoryPressure}
+}
+
+// diskLevel names the level after the mountpoint: disk_root for /,
+// disk_var_log for /var/log
+func diskLevel(disk internal.DiskInfo) Level {
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, disk.Mountpoint), "_")
+	if name == "" {
+		name = "root"
+	}
+	return Level{Name: "disk_" + name, Value: disk.UsedPercent, Warn: warnDiskPercent, Crit: criticalDiskPercent}
+}
+
+// ResourceAlerter raises critical alerts for nearly full filesystems and
+// memory pressure that puts processes at risk of the OOM killer
+type ResourceAlerter struct {
+	critical map[string]bool // resources currently over their level
+}
+
+// NewResourceAlerter creates an alerter with every resource below its level
+func NewResourceAlerter() *ResourceAlerter {
+	return &ResourceAlerter{critical: make(map[string]bool)}
+}
+
+// EvaluateAlerts checks memory and the given filesystems against the
+// critical levels and returns alerts for resources that just crossed them.
+// A resource alerts again only after dropping back below its level.
+// Sections that failed to collect are skipped.
+func (a *ResourceAlerter) EvaluateAlerts(stats *internal.SystemStats, disks []internal.DiskInfo, now time.Time) []Alert {
+	var alerts []Alert
+	transition := func(resource string, critical bool, message string) {
+		if critical && !a.critical[resource] {
+			alerts = append(alerts, Alert{Time: now, Severity: SeverityCritical, Message: message})
+		}
+		a.critical[resource] = critical
+	}
+
+	if _, failed := stats.Errors["memory"]; !failed {
+		transition("memory", memoryLevel(stats).Critical(),
+			fmt.Sprintf("memory pressure at %.0f%%, processes risk being OOM-killed", stats.Memory.Pressure))
+	}
+	if _, failed := stats.Errors["disk"]; !failed {
+		for _, disk := range disks {
+			transition("disk:"+disk.Mountpoint, diskLevel(disk).Critical(),
+				fmt.Sprintf("%s is %.1f%% full (%s free)", disk.Mountpoint, disk.UsedPercent, internal.FormatBytes(disk.Free)))
+		}
+	}
+	return alerts
+}
+
+// BandwidthAlerter fires when an interface's throughput (upload plus
+// download) stays above its threshold for a number of consecutive samples,
+// so short bursts do not cause flapping
+type BandwidthAlerter struct {
+	thresholds map[string]float64 // KB/s by interface; "*" covers the rest
+	samples    int
+	over       map[string]int // consecutive samples above the threshold
+}
+
+// ParseBandwidthThresholds parses "eth0=5000,*=20000" style specs, mapping
+// interface names (or "*" for any other interface) to KB/s thresholds
+func ParseBandwidthThresholds(spec string) (map[string]float64, error) {
+	thresholds := make(map[string]float64)
+	for _, entry := range splitList(spec) {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid bandwidth threshold %q, want interface=KBps", entry)
+		}
+		kbps, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || kbps <= 0 {
+			return nil, fmt.Errorf("invalid bandwidth threshold %q, want a positive KB/s value", entry)
+		}
+		thresholds[strings.TrimSpace(name)] = kbps
+	}
+	return thresholds, nil
+}
+
+// NewBandwidthAlerter creates an alerter that fires after samples
+// consecutive readings over the threshold
+func NewBandwidthAlerter(thresholds map[string]float64, samples int) *BandwidthAlerter {
+	if samples < 1 {
+		samples = 1
+	}
+	return &BandwidthAlerter{
+		thresholds: thresholds,
+		samples:    samples,
+		over:       make(map[string]int),
+	}
+}
+
+// threshold returns the KB/s limit for an interface, if any
+func (a *BandwidthAlerter) threshold(iface string) (float64, bool) {
+	if kbps, ok := a.thresholds[iface]; ok {
+		return kbps, true
+	}
+	kbps, ok := a.thresholds["*"]
+	return kbps, ok
+}
+
+// Check updates the per-interface counters with one sample of speeds and
+// returns the alerts that fired. An alert fires once when the streak reaches
+// the required length and again only after throughput has dropped back
+// below the threshold. Interfaces missing from speeds had no traffic.
+func (a *BandwidthAlerter) Check(speeds []internal.NetworkSpeed, now time.Time) []Alert {
+	seen := make(map[string]bool, len(speeds))
+	var alerts []Alert
+	for _, speed := range speeds {
+		limit, ok := a.threshold(speed.Interface)
+		if !ok {
+			continue
+		}
+		seen[speed.Interface] = true
+
+		total := speed.UploadKBps + speed.DownloadKBps
+		if total <= limit {
+			delete(a.over, speed.Interface)
+			continue
+		}
+		a.over[speed.Interface]++
+		if a.over[speed.Interface] == a.samples {
+			alerts = append(alerts, Alert{
+				Time: now,
+				Message: fmt.Sprintf("%s sustained %s for %d samples (threshold %s)",
+					speed.Interface, internal.FormatNetworkSpeed(total), a.samples, internal.FormatNetworkSpeed(limit)),
+			})
+		}
+	}
+	for iface := range a.over {
+		if !seen[iface] {
+			delete(a.over, iface)
+		}
+	}
+
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Message < alerts[j].Message })
+	return alerts
+}
+
+// raiseAlerts records fired alerts for the footer, sends critical ones to
+// the notifier and appends them all to the alert log
+func (app *App) raiseAlerts(alerts []Alert) {
+	if len(alerts) == 0 {
+		return
+	}
+	app.lastAlert = &alerts[len(alerts)-1]
+
+	for _, alert := range alerts {
+		app.addEvent("%s: %s", alert.Severity, alert.Message)
+		if alert.Severity == SeverityCritical {
+			if err := app.notifier.Notify(alert); err != nil {
+				log.Printf("Error sending notification: %v", err)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(alertLogPath), 0755); err != nil {
+		log.Printf("Error creating alert log directory: %v", err)
+		return
+	}
+	file, err := os.OpenFile(alertLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("Error opening alert log: %v", err)
+		return
+	}
+	defer file.Close()
+	for _, alert := range alerts {
+		fmt.Fprintln(file, alert)
+	}
+}
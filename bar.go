@@ -0,0 +1,77 @@
+// bar.go - Progress bar styles
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BarStyle selects how progress bars are drawn
+type BarStyle int
+
+const (
+	// BarGradient shades the filled part by usage level (the default)
+	BarGradient BarStyle = iota
+	// BarSolid fills with full blocks in the usage color
+	BarSolid
+	// BarASCII draws # and -, for terminals that mangle block characters
+	BarASCII
+	// BarBraille fills braille dots, eight steps per character
+	BarBraille
+)
+
+var barStyleNames = []string{"gradient", "solid", "ascii", "braille"}
+
+func (s BarStyle) String() string {
+	return barStyleNames[s]
+}
+
+// ParseBarStyle accepts the name of a style, case-insensitively
+func ParseBarStyle(value string) (BarStyle, error) {
+	for i, name := range barStyleNames {
+		if strings.EqualFold(value, name) {
+			return BarStyle(i), nil
+		}
+	}
+	return BarGradient, fmt.Errorf("unknown bar style %q, want %s", value, strings.Join(barStyleNames, ", "))
+}
+
+// brailleSteps fills a braille cell dot by dot: up the left column, then
+// up the right one
+var brailleSteps = []rune("⡀⡄⡆⡇⣇⣧⣷⣿")
+
+// renderBar draws a bracketed bar width characters wide, filled to percent
+func (app *App) renderBar(percent float64, width int, style BarStyle) string {
+	percent = min(max(percent, 0), 100)
+	filled := int(percent / 100 * float64(width))
+	color := app.getUsageColor(percent)
+
+	var bar string
+	switch style {
+	case BarSolid:
+		bar = app.colorize(strings.Repeat("█", filled), color) +
+			app.colorize(strings.Repeat("░", width-filled), ColorDim)
+	case BarASCII:
+		bar = app.colorize(strings.Repeat("#", filled), color) +
+			app.colorize(strings.Repeat("-", width-filled), ColorDim)
+	case BarBraille:
+		dots := int(percent / 100 * float64(width*len(brailleSteps)))
+		full, partial := dots/len(brailleSteps), dots%len(brailleSteps)
+		filledCells := strings.Repeat(string(brailleSteps[len(brailleSteps)-1]), full)
+		empty := width - full
+		if partial > 0 {
+			filledCells += string(brailleSteps[partial-1])
+			empty--
+		}
+		bar = app.colorize(filledCells, color) + app.colorize(strings.Repeat("⣀", empty), ColorDim)
+	default:
+		shade := app.colorize(strings.Repeat("▒", filled), app.theme.Low)
+		if percent > app.critThreshold {
+			shade = app.colorize(strings.Repeat("█", filled), app.theme.High)
+		} else if percent > app.warnThreshold {
+			shade = app.colorize(strings.Repeat("▓", filled), app.theme.Medium)
+		}
+		bar = shade + app.colorize(strings.Repeat("░", width-filled), ColorDim)
+	}
+	return "[" + bar + app.colorize("]", ColorReset)
+}
@@ -0,0 +1,297 @@
+// collector.go - Stats sources for the terminal interface
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// Collector supplies the stats the terminal interface displays, logs and
+// exports. Live collection reads the running system; replay reads snapshots
+// recorded with -record.
+type Collector interface {
+	// Advance moves to the next sample. It is called once per refresh and
+	// returns io.EOF when a replay has run out of samples.
+	Advance() error
+	SystemStats(ctx context.Context) (*internal.SystemStats, error)
+	ProcessStats(ctx context.Context) (*internal.ProcessStats, error)
+	NetworkStats(ctx context.Context) (*internal.NetworkStats, error)
+	// Usage returns the CPU and memory percentages for the sparklines
+	Usage(ctx context.Context) (cpuPercent, memPercent float64, err error)
+}
+
+// CollectTiming is how long the latest collection of each section took
+type CollectTiming struct {
+	System    time.Duration
+	Processes time.Duration
+	Network   time.Duration
+}
+
+// Total is the time one full sample of every section takes
+func (t CollectTiming) Total() time.Duration {
+	return t.System + t.Processes + t.Network
+}
+
+// milliseconds returns the timings for the _timing key of JSON exports
+func (t CollectTiming) milliseconds() map[string]float64 {
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	return map[string]float64{
+		"system_ms":    ms(t.System),
+		"processes_ms": ms(t.Processes),
+		"network_ms":   ms(t.Network),
+		"total_ms":     ms(t.Total()),
+	}
+}
+
+// liveCollector reads stats from the running system, timing each read
+type liveCollector struct {
+	timing CollectTiming
+}
+
+func (l *liveCollector) Advance() error { return nil }
+
+func (l *liveCollector) SystemStats(ctx context.Context) (*internal.SystemStats, error) {
+	defer timeSince(&l.timing.System, time.Now())
+	return internal.GetSystemStats(ctx)
+}
+
+func (l *liveCollector) ProcessStats(ctx context.Context) (*internal.ProcessStats, error) {
+	defer timeSince(&l.timing.Processes, time.Now())
+	return internal.GetProcessStats(ctx)
+}
+
+func (l *liveCollector) NetworkStats(ctx context.Context) (*internal.NetworkStats, error) {
+	defer timeSince(&l.timing.Network, time.Now())
+	return internal.GetNetworkStats(ctx)
+}
+
+func (l *liveCollector) Usage(ctx context.Context) (float64, float64, error) {
+	return internal.SampleUsage(ctx)
+}
+
+// timeSince stores the time elapsed since start in d
+func timeSince(d *time.Duration, start time.Time) {
+	*d = time.Since(start)
+}
+
+// sampleCollector keeps the first stats of each kind collected through it,
+// so that everything one refresh does (drawing, recording, InfluxDB) works
+// from the same sample instead of collecting again
+type sampleCollector struct {
+	Collector
+	system    *internal.SystemStats
+	systemErr error
+	procs     *internal.ProcessStats
+	procsErr  error
+	net       *internal.NetworkStats
+	netErr    error
+	taken     struct{ system, procs, net bool }
+}
+
+func (s *sampleCollector) Advance() error {
+	*s = sampleCollector{Collector: s.Collector}
+	return s.Collector.Advance()
+}
+
+func (s *sampleCollector) SystemStats(ctx context.Context) (*internal.SystemStats, error) {
+	if !s.taken.system {
+		s.system, s.systemErr = s.Collector.SystemStats(ctx)
+		s.taken.system = true
+	}
+	return s.system, s.systemErr
+}
+
+func (s *sampleCollector) ProcessStats(ctx context.Context) (*internal.ProcessStats, error) {
+	if !s.taken.procs {
+		s.procs, s.procsErr = s.Collector.ProcessStats(ctx)
+		s.taken.procs = true
+	}
+	return s.procs, s.procsErr
+}
+
+func (s *sampleCollector) NetworkStats(ctx context.Context) (*internal.NetworkStats, error) {
+	if !s.taken.net {
+		s.net, s.netErr = s.Collector.NetworkStats(ctx)
+		s.taken.net = true
+	}
+	return s.net, s.netErr
+}
+
+// sampling runs fn with the stats collected through a sampleCollector
+func (app *App) sampling(fn func()) {
+	sample := &sampleCollector{Collector: app.collector}
+	app.collector = sample
+	defer func() {
+		if app.collector == sample { // fn may have switched sources
+			app.collector = sample.Collector
+		}
+	}()
+	fn()
+}
+
+// source returns the collector the stats come from, seen through the
+// sample of a refresh in progress
+func (app *App) source() Collector {
+	if sample, ok := app.collector.(*sampleCollector); ok {
+		return sample.Collector
+	}
+	return app.collector
+}
+
+// collectTiming returns the latest collection times. ok is false when the
+// stats are replayed or fetched from an agent rather than collected here.
+func (app *App) collectTiming() (timing CollectTiming, ok bool) {
+	live, ok := app.source().(*liveCollector)
+	if !ok {
+		return CollectTiming{}, false
+	}
+	return live.timing, true
+}
+
+// snapshot is one recorded refresh, in the same shape as log and -stream lines
+type snapshot struct {
+	Timestamp string                 `json:"timestamp"`
+	System    *internal.SystemStats  `json:"system"`
+	Processes *internal.ProcessStats `json:"processes"`
+	Network   *internal.NetworkStats `json:"network"`
+}
+
+// errNotRecorded is returned for stats missing from a recorded snapshot
+var errNotRecorded = errors.New("not present in recording")
+
+func (s snapshot) systemStats() (*internal.SystemStats, error) {
+	if s.System != nil {
+		return s.System, nil
+	}
+	return nil, fmt.Errorf("system stats %w", errNotRecorded)
+}
+
+func (s snapshot) processStats() (*internal.ProcessStats, error) {
+	if s.Processes != nil {
+		return s.Processes, nil
+	}
+	return nil, fmt.Errorf("process stats %w", errNotRecorded)
+}
+
+func (s snapshot) networkStats() (*internal.NetworkStats, error) {
+	if s.Network != nil {
+		return s.Network, nil
+	}
+	return nil, fmt.Errorf("network stats %w", errNotRecorded)
+}
+
+// usage returns the CPU and memory usage for the sparklines, failing when
+// either section was not collected rather than reporting it as zero
+func (s snapshot) usage() (float64, float64, error) {
+	stats, err := s.systemStats()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, section := range []string{"cpu", "memory"} {
+		if reason, failed := stats.Errors[section]; failed {
+			return 0, 0, fmt.Errorf("%s stats not collected: %s", section, reason)
+		}
+	}
+	return stats.CPU.Usage, stats.Memory.UsedPercent, nil
+}
+
+// replayCollector plays back snapshots from an NDJSON recording
+type replayCollector struct {
+	snapshots []snapshot
+	pos       int
+	loop      bool
+}
+
+// newReplayCollector loads every snapshot in path. With loop set, playback
+// wraps around to the first snapshot instead of stopping at the end.
+func newReplayCollector(path string, loop bool) (*replayCollector, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var snapshots []snapshot
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // process lists make long lines
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var snap snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		snapshots = append(snapshots, snap)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("%s: no snapshots recorded", path)
+	}
+
+	return &replayCollector{snapshots: snapshots, loop: loop}, nil
+}
+
+func (r *replayCollector) Advance() error {
+	if r.pos+1 < len(r.snapshots) {
+		r.pos++
+		return nil
+	}
+	if r.loop {
+		r.pos = 0
+		return nil
+	}
+	return io.EOF
+}
+
+func (r *replayCollector) current() snapshot {
+	return r.snapshots[r.pos]
+}
+
+func (r *replayCollector) SystemStats(ctx context.Context) (*internal.SystemStats, error) {
+	return r.current().systemStats()
+}
+
+func (r *replayCollector) ProcessStats(ctx context.Context) (*internal.ProcessStats, error) {
+	return r.current().processStats()
+}
+
+func (r *replayCollector) NetworkStats(ctx context.Context) (*internal.NetworkStats, error) {
+	return r.current().networkStats()
+}
+
+func (r *replayCollector) Usage(ctx context.Context) (float64, float64, error) {
+	return r.current().usage()
+}
+
+// recordSnapshot appends the stats of the refresh to the -record file as
+// one JSON line. Called within the refresh's sampling, it records what was
+// drawn, stamped with when it was collected rather than written.
+func (app *App) recordSnapshot() {
+	stats, err := app.collector.SystemStats(app.ctx)
+	if err != nil {
+		log.Printf("Error getting stats for recording: %v", err)
+		return
+	}
+	procStats, _ := app.collector.ProcessStats(app.ctx)
+	netStats, _ := app.collector.NetworkStats(app.ctx)
+
+	entry := newLogEntry(stats, procStats, netStats)
+	if !stats.Timestamp.IsZero() {
+		entry["timestamp"] = stats.Timestamp.Format(time.RFC3339)
+	}
+	if err := json.NewEncoder(app.recordFile).Encode(entry); err != nil {
+		log.Printf("Error writing recording: %v", err)
+	}
+}
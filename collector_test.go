@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// countingCollector returns new stats from every call, like live collection
+type countingCollector struct {
+	calls    int
+	advances int
+}
+
+func (c *countingCollector) Advance() error { c.advances++; return nil }
+
+func (c *countingCollector) SystemStats(ctx context.Context) (*internal.SystemStats, error) {
+	c.calls++
+	return &internal.SystemStats{CPU: internal.CPUInfo{Usage: float64(c.calls)}}, nil
+}
+
+func (c *countingCollector) ProcessStats(ctx context.Context) (*internal.ProcessStats, error) {
+	c.calls++
+	return &internal.ProcessStats{TotalProcesses: c.calls}, nil
+}
+
+func (c *countingCollector) NetworkStats(ctx context.Context) (*internal.NetworkStats, error) {
+	c.calls++
+	return &internal.NetworkStats{}, nil
+}
+
+func (c *countingCollector) Usage(ctx context.Context) (float64, float64, error) {
+	return 0, 0, nil
+}
+
+func TestSampleCollectorCollectsOnce(t *testing.T) {
+	ctx := context.Background()
+	source := &countingCollector{}
+	sample := &sampleCollector{Collector: source}
+
+	first, _ := sample.SystemStats(ctx)
+	again, _ := sample.SystemStats(ctx)
+	procs, _ := sample.ProcessStats(ctx)
+	sample.ProcessStats(ctx)
+	sample.NetworkStats(ctx)
+	sample.NetworkStats(ctx)
+	if first != again || source.calls != 3 {
+		t.Errorf("collected %d times for one sample of each kind, want 3", source.calls)
+	}
+
+	if err := sample.Advance(); err != nil || source.advances != 1 {
+		t.Fatalf("Advance: %v, source advanced %d times", err, source.advances)
+	}
+	next, _ := sample.SystemStats(ctx)
+	nextProcs, _ := sample.ProcessStats(ctx)
+	if next == first || nextProcs == procs {
+		t.Error("Advance kept the previous sample")
+	}
+}
+
+func TestSamplingRestoresCollector(t *testing.T) {
+	source := &countingCollector{}
+	app := &App{ctx: context.Background(), collector: source}
+
+	var drawn, sent *internal.SystemStats
+	app.sampling(func() {
+		if app.source() != source {
+			t.Error("source() does not see through the sample")
+		}
+		drawn, _ = app.collector.SystemStats(app.ctx)
+		sent, _ = app.collector.SystemStats(app.ctx)
+	})
+	if drawn != sent {
+		t.Error("stats collected twice within one sample")
+	}
+	if app.collector != source {
+		t.Errorf("collector after sampling is %T, want the source back", app.collector)
+	}
+
+	// A switch of source during the sample stands
+	other := &countingCollector{}
+	app.sampling(func() { app.collector = other })
+	if app.collector != other {
+		t.Errorf("collector after switching is %T, want the new source", app.collector)
+	}
+}
+
+func TestRecordSnapshotRecordsDrawnSample(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "record.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	source := &countingCollector{}
+	app := &App{ctx: context.Background(), collector: source, recordFile: file}
+
+	var drawn *internal.SystemStats
+	app.sampling(func() {
+		drawn, _ = app.collector.SystemStats(app.ctx) // as displayInterface does
+		app.recordSnapshot()
+	})
+
+	replay, err := newReplayCollector(file.Name(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := replay.SystemStats(app.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded.CPU.Usage != drawn.CPU.Usage {
+		t.Errorf("recorded sample %v, drawn sample %v", recorded.CPU.Usage, drawn.CPU.Usage)
+	}
+	if source.calls != 3 {
+		t.Errorf("collected %d times, want once for each kind of stats", source.calls)
+	}
+}
@@ -0,0 +1,21 @@
+// commands.go - Running work from other goroutines on the main loop
+package main
+
+// The main loop goroutine owns the App: the view, pause state, refresh
+// rates and the rest are read and written there without locks. Code on
+// other goroutines, such as servers and background jobs, must not touch
+// App fields; it hands the main loop a function with Do instead.
+
+// Do runs fn on the main loop, between refreshes and key presses, and waits
+// for it to finish. It returns false, without running fn, when the app
+// shuts down first.
+func (app *App) Do(fn func()) bool {
+	done := make(chan struct{})
+	select {
+	case app.commands <- func() { defer close(done); fn() }:
+	case <-app.ctx.Done():
+		return false
+	}
+	<-done
+	return true
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newLoopApp returns an App ready for run whose refreshes and logging are
+// an hour away, so the loop only handles key presses and Do
+func newLoopApp() (*App, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &App{
+		ctx:         ctx,
+		commands:    make(chan func()),
+		out:         io.Discard,
+		refreshRate: time.Hour,
+		logInterval: time.Hour,
+	}, cancel
+}
+
+// startLoop runs the main loop in the background and returns a channel
+// closed when it exits
+func startLoop(app *App, cancel context.CancelFunc, input <-chan KeyEvent) <-chan struct{} {
+	app.cancel, app.input = cancel, input
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		app.run()
+	}()
+	return done
+}
+
+// Run with -race: App fields written through Do from many goroutines must
+// only ever be touched by the main loop
+func TestDoSerializesBackgroundWriters(t *testing.T) {
+	app, cancel := newLoopApp()
+	done := startLoop(app, cancel, nil)
+
+	const writers = 8
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !app.Do(func() { app.refreshRate += time.Second }) {
+				t.Error("Do returned false while the loop was running")
+			}
+		}()
+	}
+	wg.Wait()
+
+	var rate time.Duration
+	app.Do(func() { rate = app.refreshRate })
+	if want := time.Hour + writers*time.Second; rate != want {
+		t.Errorf("refresh rate %v, want %v", rate, want)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestDoAfterShutdown(t *testing.T) {
+	app, cancel := newLoopApp()
+	done := startLoop(app, cancel, nil)
+	cancel()
+	<-done
+
+	ran := false
+	if app.Do(func() { ran = true }) {
+		t.Error("Do returned true after the loop exited")
+	}
+	if ran {
+		t.Error("Do ran its function after the loop exited")
+	}
+}
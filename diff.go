@@ -0,0 +1,169 @@
+// diff.go - Before/after comparison of two marked snapshots
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// maxDiffProcesses bounds each process list in the diff overlay
+const maxDiffProcesses = 8
+
+// StatsDiff is the change in system stats from snapshot A to snapshot B.
+// Percentages are differences in percentage points.
+type StatsDiff struct {
+	Elapsed     time.Duration
+	CPU         float64
+	Memory      float64
+	MemoryBytes int64
+	Swap        float64
+	Disks       []DiskDelta // filesystems present in both snapshots
+}
+
+// DiskDelta is the growth of one filesystem between two snapshots
+type DiskDelta struct {
+	Mountpoint  string
+	Used        int64 // bytes, negative when space was freed
+	UsedPercent float64
+}
+
+// DiffStats compares two samples of the system stats
+func DiffStats(a, b *internal.SystemStats) *StatsDiff {
+	diff := &StatsDiff{
+		Elapsed:     b.Timestamp.Sub(a.Timestamp),
+		CPU:         b.CPU.Usage - a.CPU.Usage,
+		Memory:      b.Memory.UsedPercent - a.Memory.UsedPercent,
+		MemoryBytes: int64(b.Memory.Used) - int64(a.Memory.Used),
+		Swap:        b.Memory.SwapUsedPercent - a.Memory.SwapUsedPercent,
+	}
+	before := make(map[string]internal.DiskInfo, len(a.Disk))
+	for _, disk := range a.Disk {
+		before[disk.Mountpoint] = disk
+	}
+	for _, disk := range b.Disk {
+		old, ok := before[disk.Mountpoint]
+		if !ok || old.Unresponsive || disk.Unresponsive {
+			continue
+		}
+		diff.Disks = append(diff.Disks, DiskDelta{
+			Mountpoint:  disk.Mountpoint,
+			Used:        int64(disk.Used) - int64(old.Used),
+			UsedPercent: disk.UsedPercent - old.UsedPercent,
+		})
+	}
+	return diff
+}
+
+// networkTransferred returns the bytes sent and received between two
+// samples, or zero where a counter went backwards (an interface was reset
+// or removed)
+func networkTransferred(a, b *internal.NetworkStats) (sent, recv uint64) {
+	if b.TotalSent >= a.TotalSent {
+		sent = b.TotalSent - a.TotalSent
+	}
+	if b.TotalRecv >= a.TotalRecv {
+		recv = b.TotalRecv - a.TotalRecv
+	}
+	return sent, recv
+}
+
+// markSnapshot captures the current stats as snapshot A, or as snapshot B
+// when A is already marked, which opens the diff overlay. Pressing it with
+// the overlay open closes it and clears both marks.
+func (app *App) markSnapshot() {
+	if app.diffB != nil {
+		app.diffA, app.diffB = nil, nil
+		app.displayInterface()
+		return
+	}
+
+	stats, err := app.collector.SystemStats(app.ctx)
+	if err != nil {
+		app.statusMessage = "Snapshot failed: " + err.Error()
+		app.displayInterface()
+		return
+	}
+	procStats, _ := app.collector.ProcessStats(app.ctx)
+	netStats, _ := app.collector.NetworkStats(app.ctx)
+	snap := &snapshot{Timestamp: stats.Timestamp.Format(time.RFC3339), System: stats, Processes: procStats, Network: netStats}
+
+	if app.diffA == nil {
+		app.diffA = snap
+		app.statusMessage = "Snapshot A marked at " + stats.Timestamp.Format("15:04:05") + "; press M again to mark B"
+	} else {
+		app.diffB = snap
+		app.statusMessage = ""
+	}
+	app.displayInterface()
+}
+
+func (app *App) displaySnapshotDiff() {
+	a, b := app.diffA, app.diffB
+	fmt.Fprintf(&app.frame, "%s%s Snapshot Diff%s\n", app.colorize("", ColorBold+ColorCyan), app.icon("🔀"), app.colorize("", ColorReset))
+	fmt.Fprintln(&app.frame, app.colorize(strings.Repeat("─", 80), ColorDim))
+
+	diff := DiffStats(a.System, b.System)
+	fmt.Fprintf(&app.frame, "  A: %s   B: %s   (%s apart)\n\n",
+		app.colorize(a.System.Timestamp.Format("15:04:05"), ColorCyan),
+		app.colorize(b.System.Timestamp.Format("15:04:05"), ColorCyan),
+		diff.Elapsed.Round(time.Second))
+
+	fmt.Fprintf(&app.frame, "  CPU:     %s\n", app.signedPercent(diff.CPU))
+	fmt.Fprintf(&app.frame, "  Memory:  %s (%s)\n", app.signedPercent(diff.Memory), signedBytes(diff.MemoryBytes))
+	fmt.Fprintf(&app.frame, "  Swap:    %s\n", app.signedPercent(diff.Swap))
+	if a.Network != nil && b.Network != nil {
+		sent, recv := networkTransferred(a.Network, b.Network)
+		fmt.Fprintf(&app.frame, "  Network: %s sent, %s received\n",
+			app.colorize(internal.FormatNetworkBytes(sent), ColorYellow),
+			app.colorize(internal.FormatNetworkBytes(recv), ColorYellow))
+	}
+
+	for _, disk := range diff.Disks {
+		if disk.Used == 0 {
+			continue
+		}
+		fmt.Fprintf(&app.frame, "  Disk %-20s %s (%s)\n", app.truncateString(disk.Mountpoint, 20),
+			signedBytes(disk.Used), app.signedPercent(disk.UsedPercent))
+	}
+
+	if a.Processes != nil && b.Processes != nil {
+		started, exited := diffProcesses(a.Processes.AllProcesses, b.Processes.AllProcesses)
+		app.displayDiffProcesses("Started", started, ColorGreen)
+		app.displayDiffProcesses("Exited", exited, ColorRed)
+	}
+
+	fmt.Fprintln(&app.frame)
+	fmt.Fprint(&app.frame, app.colorize("Press M or Esc to return...", ColorDim))
+}
+
+// displayDiffProcesses lists processes under a heading with their count
+func (app *App) displayDiffProcesses(heading string, procs []internal.ProcessInfo, color string) {
+	fmt.Fprintf(&app.frame, "\n  %s: %d\n", heading, len(procs))
+	for i, proc := range procs {
+		if i == maxDiffProcesses {
+			fmt.Fprintln(&app.frame, app.colorize(fmt.Sprintf("    ... and %d more", len(procs)-maxDiffProcesses), ColorDim))
+			break
+		}
+		fmt.Fprintf(&app.frame, "    %s %s\n", app.colorize(fmt.Sprintf("%7d", proc.PID), color), app.truncateString(printable(proc.CommandLine), 66))
+	}
+}
+
+// signedPercent formats a change in percentage points, red when usage grew
+func (app *App) signedPercent(delta float64) string {
+	color := ColorGreen
+	if delta > 0 {
+		color = ColorRed
+	}
+	return app.colorize(fmt.Sprintf("%+.1f%%", delta), color)
+}
+
+// signedBytes formats a change in bytes with its sign
+func signedBytes(delta int64) string {
+	if delta < 0 {
+		return "-" + internal.FormatBytes(uint64(-delta))
+	}
+	return "+" + internal.FormatBytes(uint64(delta))
+}
@@ -0,0 +1,97 @@
+// disktrend.go - Time-to-full estimates from disk usage growth
+package main
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// diskTrendWindow is how far back the growth rate looks
+	diskTrendWindow = time.Hour
+	// diskTrendMinSpan is the history needed before estimating, so a
+	// burst of writes just after startup does not predict a full disk
+	diskTrendMinSpan = 2 * time.Minute
+	// maxTimeToFull hides estimates too far out to be worth acting on
+	maxTimeToFull = 7 * 24 * time.Hour
+)
+
+// diskSample is one reading of a filesystem's usage
+type diskSample struct {
+	At   time.Time
+	Used uint64
+	Free uint64
+}
+
+// TimeToFull fits a least-squares line through the used bytes in history
+// and returns how long the free space of the newest sample lasts at that
+// rate. ok is false when the disk is not growing, would not fill within
+// maxTimeToFull, or the samples span too little time.
+func TimeToFull(history []diskSample) (time.Duration, bool) {
+	if len(history) < 2 {
+		return 0, false
+	}
+	first, last := history[0], history[len(history)-1]
+	if last.At.Sub(first.At) < diskTrendMinSpan {
+		return 0, false
+	}
+
+	// Offsets from the first sample keep the sums small
+	var sumT, sumU, sumTT, sumTU float64
+	for _, sample := range history {
+		t := sample.At.Sub(first.At).Seconds()
+		u := float64(sample.Used) - float64(first.Used)
+		sumT += t
+		sumU += u
+		sumTT += t * t
+		sumTU += t * u
+	}
+	n := float64(len(history))
+	denom := n*sumTT - sumT*sumT
+	if denom == 0 {
+		return 0, false
+	}
+	rate := (n*sumTU - sumT*sumU) / denom // bytes per second
+	if rate <= 0 {
+		return 0, false
+	}
+	// Checked in seconds: a slow rate overflows a Duration
+	seconds := float64(last.Free) / rate
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) || seconds > maxTimeToFull.Seconds() {
+		return 0, false
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// sampleDiskUsage adds the current usage of each filesystem to its
+// history, dropping samples older than diskTrendWindow
+func (app *App) sampleDiskUsage() {
+	if !app.localStats() { // replayed and remote samples are not evenly timed
+		return
+	}
+	stats, err := app.collector.SystemStats(app.ctx)
+	if err != nil {
+		return
+	}
+	if app.diskHistory == nil {
+		app.diskHistory = make(map[string][]diskSample)
+	}
+
+	now := time.Now()
+	for _, disk := range stats.Disk {
+		if disk.Unresponsive {
+			continue
+		}
+		history := append(app.diskHistory[disk.Mountpoint], diskSample{At: now, Used: disk.Used, Free: disk.Free})
+		expired := 0
+		for expired < len(history) && now.Sub(history[expired].At) > diskTrendWindow {
+			expired++
+		}
+		app.diskHistory[disk.Mountpoint] = history[expired:]
+	}
+}
+
+// diskTimeToFull returns the estimate shown for a mountpoint, if any
+func (app *App) diskTimeToFull(mountpoint string) (time.Duration, bool) {
+	return TimeToFull(app.diskHistory[mountpoint])
+}
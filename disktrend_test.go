@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// growingDisk returns samples a minute apart of a disk gaining rate bytes
+// per second, with free bytes left at the last one
+func growingDisk(samples int, rate float64, free uint64) []diskSample {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := make([]diskSample, samples)
+	for i := range history {
+		elapsed := time.Duration(i) * time.Minute
+		history[i] = diskSample{
+			At:   start.Add(elapsed),
+			Used: uint64(1<<30 + rate*elapsed.Seconds()),
+			Free: uint64(int64(free) + int64(rate*float64(samples-1-i)*60)),
+		}
+	}
+	return history
+}
+
+func TestTimeToFull(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []diskSample
+		want    time.Duration
+		wantOK  bool
+	}{
+		{"growing", growingDisk(10, 1000, 3_600_000), time.Hour, true},
+		{"flat", growingDisk(10, 0, 1<<30), 0, false},
+		{"shrinking", growingDisk(10, -1000, 1<<30), 0, false},
+		{"too short a span", growingDisk(2, 1000, 1<<30), 0, false},
+		{"single sample", growingDisk(1, 1000, 1<<30), 0, false},
+		{"overflow", growingDisk(10, 50, 1<<40), 0, false},
+		{"past maxTimeToFull", growingDisk(10, 1000, 1<<40), 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := TimeToFull(tt.history)
+		if ok != tt.wantOK || (ok && got.Round(time.Second) != tt.want) {
+			t.Errorf("%s: TimeToFull = %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// env.go - Alert settings from environment variables
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+)
+
+// envPrefix starts the environment variables that stand in for flags
+const envPrefix = "SYSMON_"
+
+// envFlags are the threshold and alert flags that can also be set from the
+// environment, for containers where passing flags is awkward
+var envFlags = []string{"warn-threshold", "crit-threshold", "net-alert", "net-alert-samples", "notify"}
+
+// envName returns the environment variable standing in for a flag, e.g.
+// SYSMON_WARN_THRESHOLD for -warn-threshold
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnv sets the envFlags not given on the command line from their
+// environment variables. It runs after flag.Parse and before the state
+// file is applied, so flags win over the environment, which wins over
+// remembered state and the defaults. Invalid values are logged and ignored.
+func applyEnv() {
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for _, name := range envFlags {
+		value, ok := os.LookupEnv(envName(name))
+		if !ok || explicit[name] {
+			continue
+		}
+		// A failed Set can leave the value zeroed, so put the default back
+		previous := flag.Lookup(name).Value.String()
+		if err := flag.Set(name, value); err != nil {
+			log.Printf("Ignoring %s=%q: %v", envName(name), value, err)
+			flag.Lookup(name).Value.Set(previous)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// events.go - Timeline of alerts, process exits and user actions
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// maxEvents caps the event log; older events are dropped
+const maxEvents = 200
+
+// Event is one line of the event log
+type Event struct {
+	Time    time.Time
+	Message string
+}
+
+// addEvent appends a formatted message to the event log
+func (app *App) addEvent(format string, args ...any) {
+	app.events = append(app.events, Event{Time: time.Now(), Message: fmt.Sprintf(format, args...)})
+	if len(app.events) > maxEvents {
+		app.events = app.events[len(app.events)-maxEvents:]
+	}
+}
+
+// processKey identifies a process across scans despite PID reuse
+type processKey struct {
+	pid     int32
+	started int64
+}
+
+// trackProcessExits logs the exit of processes that were in the previous
+// scan's top CPU or memory lists. Exits of every process would bury the
+// log under short-lived commands.
+func (app *App) trackProcessExits() {
+	procStats, err := app.collector.ProcessStats(app.ctx)
+	if err != nil {
+		return
+	}
+	running := make(map[processKey]bool, len(procStats.AllProcesses))
+	for _, proc := range procStats.AllProcesses {
+		running[processKey{proc.PID, proc.CreateTime}] = true
+	}
+	// Without the full list every process would seem to have exited
+	if len(running) > 0 {
+		for key, name := range app.notableProcesses {
+			if !running[key] {
+				app.addEvent("Process %s (PID %d) exited", name, key.pid)
+			}
+		}
+	}
+
+	app.notableProcesses = make(map[processKey]string)
+	for _, list := range [][]internal.ProcessInfo{procStats.TopCPU, procStats.TopMemory} {
+		for _, proc := range list {
+			app.notableProcesses[processKey{proc.PID, proc.CreateTime}] = proc.Name
+		}
+	}
+}
+
+func (app *App) displayEvents() {
+	fmt.Fprintf(&app.frame, "%s%s Event Log%s\n", app.colorize("", ColorBold+ColorYellow), app.icon("📋"), app.colorize("", ColorReset))
+	fmt.Fprintln(&app.frame, app.colorize(strings.Repeat("─", 80), ColorDim))
+
+	limit := 30
+	if app.compactMode {
+		limit = 15
+	}
+	events := app.events
+	if len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	if len(events) == 0 {
+		fmt.Fprintln(&app.frame, app.colorize("  Nothing has happened yet", ColorDim))
+	}
+	for _, event := range events {
+		fmt.Fprintf(&app.frame, "  %s %s\n", app.colorize(event.Time.Format("15:04:05"), ColorCyan), app.truncateString(event.Message, 66))
+	}
+
+	fmt.Fprintln(&app.frame)
+	fmt.Fprint(&app.frame, app.colorize("Press J or Esc to return...", ColorDim))
+}
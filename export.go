@@ -0,0 +1,341 @@
+// export.go - One-shot stats export to JSON or CSV
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// defaultExportPath keeps exports in timestamped files under exports/
+const defaultExportPath = "exports/"
+
+// exportPrefix starts the name of each timestamped export file
+const exportPrefix = "sysmon_export_"
+
+// Format selects the file format of an export
+type Format int
+
+const (
+	FormatJSON Format = iota
+	FormatCSV
+)
+
+// exportFormatNames are the formats' names in -export-formats, which are
+// also their file extensions
+var exportFormatNames = map[Format]string{FormatJSON: "json", FormatCSV: "csv"}
+
+func (f Format) String() string {
+	return exportFormatNames[f]
+}
+
+// ParseExportFormats parses a comma-separated list such as "json,csv",
+// case-insensitively, dropping repeats
+func ParseExportFormats(spec string) ([]Format, error) {
+	var formats []Format
+	seen := make(map[Format]bool)
+	for _, name := range splitList(spec) {
+		format, ok := Format(0), false
+		for f, fname := range exportFormatNames {
+			if strings.EqualFold(name, fname) {
+				format, ok = f, true
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown export format %q, want json or csv", name)
+		}
+		if !seen[format] {
+			seen[format] = true
+			formats = append(formats, format)
+		}
+	}
+	return formats, nil
+}
+
+// ExportDetail selects how much of the process list an export contains
+type ExportDetail int
+
+const (
+	// ExportSummary keeps only the top CPU, memory and I/O lists
+	ExportSummary ExportDetail = iota
+	// ExportFull keeps every process and drops the top lists, whose
+	// entries would all be duplicates
+	ExportFull
+)
+
+// ParseExportDetail accepts "summary" or "full", case-insensitively
+func ParseExportDetail(value string) (ExportDetail, error) {
+	switch strings.ToLower(value) {
+	case "summary":
+		return ExportSummary, nil
+	case "full":
+		return ExportFull, nil
+	}
+	return ExportSummary, fmt.Errorf("unknown export detail %q", value)
+}
+
+// trimProcesses returns a copy of stats holding only the process lists
+// wanted at the given detail
+func trimProcesses(stats *internal.ProcessStats, detail ExportDetail) *internal.ProcessStats {
+	if stats == nil {
+		return nil
+	}
+	trimmed := *stats
+	if detail == ExportFull {
+		trimmed.TopCPU, trimmed.TopMemory, trimmed.TopIO = nil, nil, nil
+	} else {
+		trimmed.AllProcesses = nil
+	}
+	return &trimmed
+}
+
+// FormatForPath picks the export format from a file extension, defaulting
+// to JSON for anything other than .csv
+func FormatForPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return FormatCSV
+	}
+	return FormatJSON
+}
+
+// exportTarget is one file of an export
+type exportTarget struct {
+	Path   string
+	Format Format
+}
+
+// isExportDir reports whether an -export-path setting names a directory:
+// an existing one, or a path ending in a separator
+func isExportDir(path string) bool {
+	info, err := os.Stat(path)
+	return (err == nil && info.IsDir()) || strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(os.PathSeparator))
+}
+
+// exportTargets turns the -export-path setting into the files of one
+// export. A directory gets timestamped file names inside it, one per
+// format; a file path has its extension replaced for each format. Without
+// formats, a file's format follows its extension and a directory gets JSON.
+func exportTargets(path string, formats []Format) []exportTarget {
+	if path == "" {
+		path = defaultExportPath
+	}
+	if isExportDir(path) {
+		if len(formats) == 0 {
+			formats = []Format{FormatJSON}
+		}
+		// One timestamp for all, so the files of an export pair up
+		stamp := time.Now().Format("20060102_150405")
+		targets := make([]exportTarget, len(formats))
+		for i, format := range formats {
+			targets[i] = exportTarget{filepath.Join(path, fmt.Sprintf("%s%s.%s", exportPrefix, stamp, format)), format}
+		}
+		return targets
+	}
+	if len(formats) == 0 {
+		return []exportTarget{{path, FormatForPath(path)}}
+	}
+	targets := make([]exportTarget, len(formats))
+	for i, format := range formats {
+		targets[i] = exportTarget{strings.TrimSuffix(path, filepath.Ext(path)) + "." + format.String(), format}
+	}
+	return targets
+}
+
+// pruneExports deletes the oldest timestamped exports of each format in
+// dir beyond the newest keep. keep <= 0 keeps them all; other files are
+// never touched.
+func pruneExports(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	for _, ext := range exportFormatNames {
+		// The timestamp in the name makes name order the creation order
+		names, err := filepath.Glob(filepath.Join(dir, exportPrefix+"*."+ext))
+		if err != nil {
+			return err
+		}
+		if len(names) <= keep {
+			continue
+		}
+		sort.Strings(names)
+		for _, name := range names[:len(names)-keep] {
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exportData is one collection of the stats, written out in each format
+// of an export
+type exportData struct {
+	Collected time.Time
+	System    *internal.SystemStats
+	Processes *internal.ProcessStats
+	Network   *internal.NetworkStats
+}
+
+// collectExport gathers the stats an export writes
+func (app *App) collectExport() (*exportData, error) {
+	stats, err := app.collector.SystemStats(app.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting stats for export: %w", err)
+	}
+	procStats, _ := app.collector.ProcessStats(app.ctx)
+	netStats, _ := app.collector.NetworkStats(app.ctx)
+	return &exportData{Collected: time.Now(), System: stats, Processes: procStats, Network: netStats}, nil
+}
+
+// ExportTo collects the current stats and writes them to path, creating
+// parent directories as needed
+func (app *App) ExportTo(path string, format Format) error {
+	_, err := app.ExportAll([]exportTarget{{path, format}})
+	return err
+}
+
+// ExportAll collects the current stats once and writes them to each
+// target, so the files hold the same snapshot. A target that fails does
+// not stop the others; the paths written are returned along with the
+// failures joined into one error.
+func (app *App) ExportAll(targets []exportTarget) ([]string, error) {
+	data, err := app.collectExport()
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	var errs []error
+	for _, target := range targets {
+		if err := app.writeExport(target, data); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target.Format, err))
+			continue
+		}
+		written = append(written, target.Path)
+	}
+	return written, errors.Join(errs...)
+}
+
+// writeExport writes one file of an export
+func (app *App) writeExport(target exportTarget, data *exportData) error {
+	if dir := filepath.Dir(target.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating export directory: %w", err)
+		}
+	}
+
+	file, err := os.Create(target.Path)
+	if err != nil {
+		return fmt.Errorf("creating export file: %w", err)
+	}
+
+	switch target.Format {
+	case FormatCSV:
+		err = writeExportCSV(file, data.System, data.Processes, data.Network)
+	default:
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		export := map[string]interface{}{
+			"schema_version":   SchemaVersion,
+			"export_timestamp": data.Collected.Format(time.RFC3339),
+			"version":          Version,
+			"commit":           Commit,
+			"system":           data.System,
+			"processes":        trimProcesses(data.Processes, app.exportDetail),
+			"network":          data.Network,
+			"view":             app.currentView,
+			"refresh_rate":     app.refreshRate.String(),
+		}
+		if timing, ok := app.collectTiming(); ok {
+			export["_timing"] = timing.milliseconds()
+		}
+		err = encoder.Encode(export)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("writing export file: %w", err)
+	}
+	return nil
+}
+
+// exportOnce writes one export in the -export-formats formats to
+// -export-path without starting a UI, printing each path written to w
+func exportOnce(ctx context.Context, w io.Writer, opts *Options) error {
+	formats, err := ParseExportFormats(opts.ExportFormats)
+	if err != nil {
+		return err
+	}
+	app := &App{ctx: ctx, collector: &liveCollector{}, refreshRate: opts.RefreshRate, exportKeep: opts.ExportKeep}
+	if app.exportDetail, err = ParseExportDetail(opts.ExportDetail); err != nil {
+		return err
+	}
+
+	written, err := app.ExportAll(exportTargets(opts.ExportPath, formats))
+	for _, path := range written {
+		fmt.Fprintln(w, path)
+	}
+	if len(written) > 0 && isExportDir(opts.ExportPath) {
+		if err := pruneExports(filepath.Dir(written[0]), app.exportKeep); err != nil {
+			log.Printf("Error pruning old exports: %v", err)
+		}
+	}
+	return err
+}
+
+// writeExportCSV writes a flat metric,value table of the headline stats
+func writeExportCSV(file *os.File, stats *internal.SystemStats, procStats *internal.ProcessStats, netStats *internal.NetworkStats) error {
+	formatFloat := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	formatUint := func(v uint64) string { return strconv.FormatUint(v, 10) }
+
+	rows := [][]string{
+		{"metric", "value"},
+		{"schema_version", strconv.Itoa(SchemaVersion)},
+		{"timestamp", stats.Timestamp.Format(time.RFC3339)},
+		{"host.hostname", stats.Host.Hostname},
+		{"host.uptime_seconds", formatUint(stats.Host.Uptime)},
+		{"cpu.usage_percent", formatFloat(stats.CPU.Usage)},
+		{"cpu.cores", strconv.Itoa(stats.CPU.Cores)},
+		{"memory.total_bytes", formatUint(stats.Memory.Total)},
+		{"memory.used_bytes", formatUint(stats.Memory.Used)},
+		{"memory.used_percent", formatFloat(stats.Memory.UsedPercent)},
+	}
+	for _, disk := range stats.Disk {
+		rows = append(rows,
+			[]string{"disk." + disk.Mountpoint + ".total_bytes", formatUint(disk.Total)},
+			[]string{"disk." + disk.Mountpoint + ".used_bytes", formatUint(disk.Used)},
+			[]string{"disk." + disk.Mountpoint + ".used_percent", formatFloat(disk.UsedPercent)},
+			[]string{"disk." + disk.Mountpoint + ".read_only", strconv.FormatBool(disk.ReadOnly)})
+	}
+	if procStats != nil {
+		rows = append(rows,
+			[]string{"processes.total", strconv.Itoa(procStats.TotalProcesses)},
+			[]string{"processes.running", strconv.Itoa(procStats.RunningProcs)},
+			[]string{"processes.sleeping", strconv.Itoa(procStats.SleepingProcs)},
+			[]string{"processes.disk_wait", strconv.Itoa(procStats.DiskWaitProcs)},
+			[]string{"processes.stopped", strconv.Itoa(procStats.StoppedProcs)},
+			[]string{"processes.zombie", strconv.Itoa(procStats.ZombieProcs)})
+	}
+	if netStats != nil {
+		rows = append(rows,
+			[]string{"network.bytes_sent", formatUint(netStats.TotalSent)},
+			[]string{"network.bytes_recv", formatUint(netStats.TotalRecv)},
+			[]string{"network.connections", strconv.Itoa(netStats.Connections)})
+	}
+
+	w := csv.NewWriter(file)
+	w.WriteAll(rows)
+	return w.Error()
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPruneExportsKeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	stamps := []string{"20240101_090000", "20240301_120000", "20240102_235959", "20241231_000000", "20240301_115959"}
+	others := []string{"notes.json", "sysmon_export_keep.txt", "report.csv"}
+	for _, stamp := range stamps {
+		for _, ext := range []string{"json", "csv"} {
+			writeTestFile(t, filepath.Join(dir, exportPrefix+stamp+"."+ext))
+		}
+	}
+	for _, name := range others {
+		writeTestFile(t, filepath.Join(dir, name))
+	}
+	// Fewer CSV exports than keep: none of those go
+	os.Remove(filepath.Join(dir, exportPrefix+"20240101_090000.csv"))
+	os.Remove(filepath.Join(dir, exportPrefix+"20240102_235959.csv"))
+	os.Remove(filepath.Join(dir, exportPrefix+"20240301_115959.csv"))
+
+	if err := pruneExports(dir, 3); err != nil {
+		t.Fatalf("pruneExports: %v", err)
+	}
+
+	want := []string{
+		"notes.json", "report.csv",
+		exportPrefix + "20240301_115959.json",
+		exportPrefix + "20240301_120000.csv",
+		exportPrefix + "20240301_120000.json",
+		exportPrefix + "20241231_000000.csv",
+		exportPrefix + "20241231_000000.json",
+		"sysmon_export_keep.txt",
+	}
+	if got := dirNames(t, dir); !slices.Equal(got, want) {
+		t.Errorf("after pruning to 3:\n got %v\nwant %v", got, want)
+	}
+
+	if err := pruneExports(dir, 1); err != nil {
+		t.Fatalf("pruneExports: %v", err)
+	}
+	want = []string{
+		"notes.json", "report.csv",
+		exportPrefix + "20241231_000000.csv",
+		exportPrefix + "20241231_000000.json",
+		"sysmon_export_keep.txt",
+	}
+	if got := dirNames(t, dir); !slices.Equal(got, want) {
+		t.Errorf("after pruning to 1:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestPruneExportsKeepZero(t *testing.T) {
+	dir := t.TempDir()
+	for _, stamp := range []string{"20240101_000000", "20240102_000000"} {
+		writeTestFile(t, filepath.Join(dir, exportPrefix+stamp+".json"))
+	}
+	if err := pruneExports(dir, 0); err != nil {
+		t.Fatalf("pruneExports: %v", err)
+	}
+	if got := dirNames(t, dir); len(got) != 2 {
+		t.Errorf("keep 0 removed files: %v left", got)
+	}
+}
+
+func writeTestFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// dirNames lists the files in dir, sorted by name
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
@@ -1,4 +1,4 @@
-module sysmon
+module github.com/imunderthetree/sysmon
 
 go 1.25.0
 
@@ -6,6 +6,7 @@ require (
 	fyne.io/fyne/v2 v2.4.5
 	github.com/getlantern/systray v1.2.2
 	github.com/shirou/gopsutil/v3 v3.24.5
+	golang.org/x/term v0.20.0
 )
 
 require (
@@ -0,0 +1,51 @@
+// gpu.go - Background GPU queries for the System view
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal/gpu"
+)
+
+// getGPUInfo runs nvidia-smi, which can take up to its 5 second timeout
+var getGPUInfo = gpu.GetGPUInfo
+
+// queryGPUs refreshes the GPU stats the System view shows. The query runs
+// in the background and hands its result to the main loop with Do, so a
+// slow nvidia-smi never holds up drawing; until it answers, the view shows
+// the previous result. Queries are at most one refresh interval apart.
+func (app *App) queryGPUs() {
+	if !app.localStats() || app.queryingGPUs || time.Since(app.gpusQueried) < app.currentRefreshRate() {
+		return
+	}
+	app.queryingGPUs = true
+	ctx := app.ctx
+	go func() {
+		gpus, err := getGPUInfo(ctx)
+		app.Do(func() { app.setGPUs(gpus, err) })
+	}()
+}
+
+// setGPUs takes the result of a GPU query. An error is logged when it
+// first appears rather than on every query; the GPU section shows it
+// meanwhile. The System view is redrawn only when the section appears,
+// disappears or changes error, since the next refresh picks up new values.
+func (app *App) setGPUs(gpus []gpu.GPUInfo, err error) {
+	changed := errorText(err) != errorText(app.gpuErr) || len(gpus) != len(app.gpus)
+	if err != nil && changed {
+		log.Printf("Error getting GPU info: %v", err)
+	}
+	app.queryingGPUs, app.gpusQueried = false, time.Now()
+	app.gpus, app.gpuErr = gpus, err
+	if changed && app.currentView == ViewSystem {
+		app.displayInterface()
+	}
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal/gpu"
+)
+
+func TestGPUErrorLoggedOnce(t *testing.T) {
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+
+	app := &App{currentView: ViewOverview}
+	failure := errors.New("nvidia-smi: exit status 9")
+	for range 3 {
+		app.setGPUs(nil, failure)
+	}
+	if n := strings.Count(logged.String(), "Error getting GPU info"); n != 1 {
+		t.Errorf("logged the error %d times, want once:\n%s", n, logged.String())
+	}
+	if app.gpuErr != failure {
+		t.Errorf("gpuErr = %v, want the query's error for the GPU section", app.gpuErr)
+	}
+
+	// Recovering and failing again is news
+	app.setGPUs([]gpu.GPUInfo{{Name: "Tesla T4"}}, nil)
+	app.setGPUs(nil, failure)
+	if n := strings.Count(logged.String(), "Error getting GPU info"); n != 2 {
+		t.Errorf("logged the error %d times after it came back, want twice", n)
+	}
+}
+
+func TestQueryGPUsInBackground(t *testing.T) {
+	release := make(chan struct{})
+	getGPUInfo = func(ctx context.Context) ([]gpu.GPUInfo, error) {
+		<-release
+		return []gpu.GPUInfo{{Name: "Tesla T4"}}, nil
+	}
+	defer func() { getGPUInfo = gpu.GetGPUInfo }()
+
+	app, cancel := newLoopApp()
+	app.currentView = ViewOverview
+	done := startLoop(app, cancel, nil)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	var started time.Time
+	var querying bool
+	app.Do(func() {
+		started = time.Now()
+		app.queryGPUs()
+		app.queryGPUs() // already querying: skipped
+		querying = app.queryingGPUs
+	})
+	if elapsed := time.Since(started); elapsed > 200*time.Millisecond {
+		t.Errorf("queryGPUs held up the main loop for %v", elapsed)
+	}
+	if !querying {
+		t.Fatal("queryGPUs did not start a query")
+	}
+	close(release)
+
+	var gpus []gpu.GPUInfo
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		app.Do(func() {
+			if !app.queryingGPUs {
+				gpus = app.gpus
+			}
+		})
+		if gpus != nil {
+			break
+		}
+	}
+	if len(gpus) != 1 || gpus[0].Name != "Tesla T4" {
+		t.Errorf("gpus %+v, want the query's result", gpus)
+	}
+
+	// The result is fresh for a refresh interval
+	app.Do(func() {
+		app.queryGPUs()
+		querying = app.queryingGPUs
+	})
+	if querying {
+		t.Error("queryGPUs queried again within the refresh interval")
+	}
+}
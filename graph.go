@@ -0,0 +1,181 @@
+// graph.go - Time-series graph view of CPU, memory and network
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// Chart layout; each cell holds two samples across and four dots down
+const (
+	graphWidth         = 68 // cells, leaving room for the axis labels
+	graphHeight        = 5
+	graphCompactHeight = 3
+)
+
+// Allowed -graph-window range
+const (
+	minGraphWindow     = time.Minute
+	maxGraphWindow     = time.Hour
+	defaultGraphWindow = 5 * time.Minute
+)
+
+// graphSample is one refresh's readings for the graph view
+type graphSample struct {
+	At      time.Time
+	CPU     float64
+	Memory  float64
+	NetKBps float64 // upload plus download over all interfaces, NaN when not measured
+}
+
+// recordGraph appends this refresh's readings to the graph history,
+// dropping samples older than the graph window
+func (app *App) recordGraph() {
+	cpuValues, memValues := app.cpuHistory.Values(), app.memHistory.Values()
+	// Like the peaks, skip the first CPU sample, which spans only startup
+	if len(cpuValues) < 2 || len(memValues) == 0 {
+		return
+	}
+
+	sample := graphSample{At: time.Now(), CPU: cpuValues[len(cpuValues)-1], Memory: memValues[len(memValues)-1], NetKBps: math.NaN()}
+	if app.localStats() { // speeds are not recorded
+		sample.NetKBps = 0
+		for _, speed := range app.netSpeeds {
+			sample.NetKBps += speed.UploadKBps + speed.DownloadKBps
+		}
+	}
+
+	samples := append(app.graphSamples, sample)
+	expired := 0
+	for expired < len(samples) && sample.At.Sub(samples[expired].At) > app.graphWindow {
+		expired++
+	}
+	app.graphSamples = samples[expired:]
+}
+
+// graphSeries resamples one reading of the graph history onto the chart's
+// points, which divide the window evenly up to now. Each point takes the
+// latest sample at or before its time; points before the first sample are
+// NaN and left blank.
+func (app *App) graphSeries(value func(graphSample) float64) []float64 {
+	points := 2 * graphWidth
+	series := make([]float64, points)
+	now := time.Now()
+	step := app.graphWindow / time.Duration(points-1)
+
+	next := 0
+	current := math.NaN()
+	for i := range series {
+		at := now.Add(-app.graphWindow + time.Duration(i)*step)
+		for next < len(app.graphSamples) && !app.graphSamples[next].At.After(at) {
+			current = value(app.graphSamples[next])
+			next++
+		}
+		series[i] = current
+	}
+	// The newest sample belongs in the last column even if it is a moment
+	// younger than now was when the loop started
+	if n := len(app.graphSamples); n > 0 {
+		series[points-1] = value(app.graphSamples[n-1])
+	}
+	return series
+}
+
+func (app *App) displayGraphView() {
+	height := graphHeight
+	if app.compactMode {
+		height = graphCompactHeight
+	}
+
+	cpu := app.graphSeries(func(s graphSample) float64 { return s.CPU })
+	memory := app.graphSeries(func(s graphSample) float64 { return s.Memory })
+	app.displayChart("🔧", "CPU", cpu, height, true, app.graphPercentLabel)
+	app.displayChart("💾", "Memory", memory, height, true, app.graphPercentLabel)
+
+	if !app.localStats() {
+		fmt.Fprintf(&app.frame, "%s%s Network%s\n", app.colorize("", ColorBold+ColorBlue), app.icon("🌐"), app.colorize("", ColorReset))
+		fmt.Fprintln(&app.frame, app.colorize("  Network speeds are not recorded, so they cannot be graphed here", ColorDim))
+	} else {
+		// Network speeds have no natural ceiling, so chart them against
+		// the busiest moment in the window
+		network := app.graphSeries(func(s graphSample) float64 { return s.NetKBps })
+		peak := 0.0
+		for _, v := range network {
+			if !math.IsNaN(v) {
+				peak = max(peak, v)
+			}
+		}
+		scaled := make([]float64, len(network))
+		for i, v := range network {
+			scaled[i] = v
+			if peak > 0 {
+				scaled[i] = v / peak * 100
+			}
+		}
+		app.displayChart("🌐", "Network", scaled, height, false, func(percent float64) string {
+			return internal.FormatNetworkSpeed(peak * percent / 100)
+		})
+	}
+
+	// Time axis shared by the charts
+	left := "-" + shortDuration(app.graphWindow)
+	middle := "-" + shortDuration((app.graphWindow / 2).Round(time.Second))
+	gap := (graphWidth - len(left) - len(middle) - len("now")) / 2
+	fmt.Fprintf(&app.frame, "%12s%s%s%s%s%s\n", "",
+		left, strings.Repeat(" ", gap), middle, strings.Repeat(" ", graphWidth-len(left)-len(middle)-len("now")-gap), "now")
+}
+
+// shortDuration formats d without zero trailing units, e.g. 5m rather than 5m0s
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// graphPercentLabel labels a percentage axis
+func (app *App) graphPercentLabel(percent float64) string {
+	return fmt.Sprintf("%.0f%%", percent)
+}
+
+// displayChart draws one titled chart with its top and bottom values
+// labelled on the left axis. usageColors colors the line by the latest
+// value like other usage percentages.
+func (app *App) displayChart(icon, title string, series []float64, height int, usageColors bool, label func(float64) string) {
+	latest := math.NaN()
+	top := 100.0
+	for _, v := range series {
+		if !math.IsNaN(v) {
+			latest = v
+			top = max(top, v)
+		}
+	}
+	now := "no data yet"
+	if !math.IsNaN(latest) {
+		now = label(latest)
+	}
+	fmt.Fprintf(&app.frame, "%s%s %s%s %s\n", app.colorize("", ColorBold+ColorBlue), app.icon(icon), title, app.colorize("", ColorReset), app.colorize(now, ColorCyan))
+
+	color := ColorCyan
+	if usageColors && !math.IsNaN(latest) {
+		color = app.getUsageColor(latest)
+	}
+	for row, line := range internal.BrailleChart(series, graphWidth, height) {
+		axis := ""
+		switch row {
+		case 0:
+			axis = label(top)
+		case height - 1:
+			axis = label(0)
+		}
+		fmt.Fprintf(&app.frame, "%10s %s%s\n", axis, app.colorize("┤", ColorDim), app.colorize(line, color))
+	}
+}
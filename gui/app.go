@@ -1,15 +1,16 @@
 package gui
 
 import (
+	"context"
 	"fmt"
 	"sync"
-	"sysmon/internal"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+	"github.com/imunderthetree/sysmon/internal"
 )
 
 // HistoryPoint represents a single data point for charting
@@ -58,12 +59,17 @@ type AppState struct {
 	// UI state
 	currentTheme ThemeMode
 	mutex        sync.RWMutex
+
+	// safe is -safe: actions that change the system, such as terminating
+	// processes, are refused
+	safe bool
 }
 
-// NewApp creates and initializes a new GUI application
-func NewApp() *AppState {
+// NewApp creates and initializes a new GUI application. With safe set it
+// runs read-only, like the terminal UI with -safe.
+func NewApp(safe bool) *AppState {
 	fyneApp := app.NewWithID("sysmon")
-	mainWindow := fyneApp.NewWindow()
+	mainWindow := fyneApp.NewWindow("System Monitor")
 	mainWindow.Resize(fyne.NewSize(1200, 700))
 
 	state := &AppState{
@@ -73,6 +79,7 @@ func NewApp() *AppState {
 		paused:      false,
 		stopChan:    make(chan bool),
 		currentTheme: ThemeLight,
+		safe:         safe,
 
 		cpuHistory:       make([]*HistoryPoint, 0, 60),
 		memoryHistory:    make([]*HistoryPoint, 0, 60),
@@ -163,8 +170,10 @@ func (s *AppState) fetchData() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	ctx := context.Background()
+
 	// Get system stats
-	if stats, err := internal.GetSystemStats(); err == nil {
+	if stats, err := internal.GetSystemStats(ctx); err == nil {
 		s.systemStats = stats
 
 		// Add to history (keep last 60 points)
@@ -186,17 +195,17 @@ func (s *AppState) fetchData() {
 	}
 
 	// Get process stats
-	if pstats, err := internal.GetProcessStats(); err == nil {
+	if pstats, err := internal.GetProcessStats(ctx); err == nil {
 		s.processStats = pstats
 	}
 
 	// Get network stats
-	if nstats, err := internal.GetNetworkStats(); err == nil {
+	if nstats, err := internal.GetNetworkStats(ctx); err == nil {
 		s.networkStats = nstats
 	}
 
 	// Get network speeds
-	if speeds, err := internal.GetNetworkSpeeds(); err == nil && len(speeds) > 0 {
+	if speeds, err := internal.GetNetworkSpeeds(ctx, 0); err == nil && len(speeds) > 0 {
 		totalDown := 0.0
 		for _, speed := range speeds {
 			totalDown += speed.DownloadKBps
@@ -222,6 +231,9 @@ func (s *AppState) updateDisplay() {
 			s.systemStats.CPU.Usage,
 			s.systemStats.Memory.UsedPercent,
 			time.Now().Format("15:04:05"))
+		if s.safe {
+			status += " | read-only mode"
+		}
 		s.statusLabel.SetText(status)
 	}
 
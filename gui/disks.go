@@ -3,12 +3,12 @@ package gui
 import (
 	"fmt"
 	"image/color"
-	"sysmon/internal"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+	"github.com/imunderthetree/sysmon/internal"
 )
 
 // DisksTab represents the disks view
@@ -2,12 +2,12 @@ package gui
 
 import (
 	"image/color"
-	"sysmon/internal"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+	"github.com/imunderthetree/sysmon/internal"
 )
 
 // NetworkTab represents the network view
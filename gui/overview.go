@@ -3,13 +3,13 @@ package gui
 import (
 	"fmt"
 	"image/color"
-	"sysmon/internal"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+	"github.com/imunderthetree/sysmon/internal"
 )
 
 // OverviewTab represents the overview/dashboard view
@@ -88,8 +88,13 @@ func (s *AppState) newProcessesTab() fyne.CanvasObject {
 	return scroll
 }
 
-// killProcessWithConfirm shows a confirmation dialog and kills the process
+// killProcessWithConfirm shows a confirmation dialog and kills the
+// process. In read-only mode it only says that terminating is disabled.
 func (tab *ProcessesTab) killProcessWithConfirm(pid int32, name string) {
+	if tab.appState.safe {
+		dialog.ShowInformation("Terminate Process", "Terminating processes is disabled in read-only mode (-safe)", tab.window)
+		return
+	}
 	message := fmt.Sprintf("Are you sure you want to terminate process '%s' (PID: %d)?", name, pid)
 	confirm := dialog.NewConfirm("Terminate Process", message, func(confirmed bool) {
 		if confirmed {
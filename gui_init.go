@@ -4,10 +4,10 @@
 package main
 
 import (
-	"sysmon/gui"
+	"github.com/imunderthetree/sysmon/gui"
 )
 
-func initGUI() {
-	guiApp := gui.NewApp()
+func initGUI(safe bool) {
+	guiApp := gui.NewApp(safe)
 	guiApp.Run()
 }
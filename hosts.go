@@ -0,0 +1,287 @@
+// hosts.go - Multi-host dashboard fed by -serve agents
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// hostTimeout bounds each request to an agent, so a dead host shows up as
+// down instead of stalling the grid
+const hostTimeout = 2 * time.Second
+
+// defaultAgentPort is used for -hosts entries without a port
+const defaultAgentPort = "7070"
+
+// HostSummary is one row of the hosts grid
+type HostSummary struct {
+	Host   string
+	CPU    float64 // percent of the CPU quota when the agent runs in a limited cgroup
+	Memory float64
+	Disk   float64  // fullest filesystem
+	Failed []string // sections the agent could not collect, left at zero
+	Err    error    // set when the agent could not be reached
+}
+
+// Worst is the highest of the usage percentages, which decides the row's health
+func (h HostSummary) Worst() float64 {
+	return max(h.CPU, h.Memory, h.Disk)
+}
+
+func summarizeHost(host string, stats *internal.SystemStats) HostSummary {
+	summary := HostSummary{Host: host}
+	for _, section := range []string{"cpu", "memory", "disk"} {
+		if _, failed := stats.Errors[section]; failed {
+			summary.Failed = append(summary.Failed, section)
+		}
+	}
+	switch {
+	case summary.failed("cpu"):
+	case stats.CPU.CgroupCPUQuota > 0:
+		summary.CPU = stats.CPU.CgroupUsage
+	default:
+		summary.CPU = stats.CPU.Usage
+	}
+	switch {
+	case summary.failed("memory"):
+	case stats.Memory.CgroupMemLimit > 0:
+		summary.Memory = stats.Memory.CgroupUsedPercent
+	default:
+		summary.Memory = stats.Memory.UsedPercent
+	}
+	if !summary.failed("disk") {
+		for _, disk := range stats.Disk {
+			summary.Disk = max(summary.Disk, disk.UsedPercent)
+		}
+	}
+	return summary
+}
+
+func (h HostSummary) failed(section string) bool {
+	return slices.Contains(h.Failed, section)
+}
+
+// agentURL turns a -hosts entry (host, host:port or a URL) into the
+// address of the agent's stats endpoint
+func agentURL(host string) string {
+	if strings.Contains(host, "://") {
+		return strings.TrimSuffix(host, "/") + "/stats"
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, defaultAgentPort)
+	}
+	return "http://" + host + "/stats"
+}
+
+// fetchSnapshot asks an agent for a fresh sample
+func fetchSnapshot(ctx context.Context, host string) (snapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, hostTimeout)
+	defer cancel()
+
+	var snap snapshot
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, agentURL(host), nil)
+	if err != nil {
+		return snap, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err // the URL is already in the grid
+		}
+		return snap, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return snap, fmt.Errorf("%s: %s", host, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&snap); err != nil {
+		return snap, fmt.Errorf("%s: %w", host, err)
+	}
+	return snap, nil
+}
+
+// pollAgents fetches every host concurrently and returns their summaries in
+// the order given
+func pollAgents(ctx context.Context, hosts []string) []HostSummary {
+	summaries := make([]HostSummary, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			snap, err := fetchSnapshot(ctx, host)
+			if err == nil {
+				var stats *internal.SystemStats
+				if stats, err = snap.systemStats(); err == nil {
+					summaries[i] = summarizeHost(host, stats)
+					return
+				}
+			}
+			summaries[i] = HostSummary{Host: host, Err: err}
+		}()
+	}
+	wg.Wait()
+	return summaries
+}
+
+// remoteCollector shows the stats of one agent, fetched once per refresh
+type remoteCollector struct {
+	host string
+	snap snapshot
+	err  error // why the last fetch failed
+}
+
+func (r *remoteCollector) Advance() error {
+	r.snap, r.err = fetchSnapshot(context.Background(), r.host)
+	return r.err
+}
+
+func (r *remoteCollector) SystemStats(ctx context.Context) (*internal.SystemStats, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.snap.systemStats()
+}
+
+func (r *remoteCollector) ProcessStats(ctx context.Context) (*internal.ProcessStats, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.snap.processStats()
+}
+
+func (r *remoteCollector) NetworkStats(ctx context.Context) (*internal.NetworkStats, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.snap.networkStats()
+}
+
+func (r *remoteCollector) Usage(ctx context.Context) (float64, float64, error) {
+	if r.err != nil {
+		return 0, 0, r.err
+	}
+	return r.snap.usage()
+}
+
+// showingHosts reports whether the hosts grid is on screen rather than the
+// views of one host
+func (app *App) showingHosts() bool {
+	return len(app.hosts) > 0 && app.remoteHost == ""
+}
+
+// localStats reports whether the views show this machine's live stats, so
+// that local-only details (ports, disk I/O, GPUs, renice) apply
+func (app *App) localStats() bool {
+	return !app.replaying && app.remoteHost == ""
+}
+
+// pollHosts polls the agents in the background and hands the summaries to
+// the main loop with Do, so hosts that are slow to answer never hold up
+// the screen. While a poll is still running, the next one is skipped.
+func (app *App) pollHosts() {
+	if !app.showingHosts() || app.pollingHosts {
+		return
+	}
+	app.pollingHosts = true
+	ctx, hosts := app.ctx, app.hosts
+	go func() {
+		summaries := pollAgents(ctx, hosts)
+		app.Do(func() {
+			app.pollingHosts = false
+			app.hostSummaries = summaries
+			if app.showingHosts() {
+				app.displayInterface()
+			}
+		})
+	}()
+}
+
+// openHost switches the views to the stats of a -hosts agent
+func (app *App) openHost(host string) {
+	app.remoteHost = host
+	app.addEvent("Opened host %s", host)
+	app.switchCollector(&remoteCollector{host: host})
+}
+
+// closeHost returns from a host's views to the hosts grid
+func (app *App) closeHost() {
+	app.addEvent("Closed host %s", app.remoteHost)
+	app.remoteHost = ""
+	app.switchCollector(&liveCollector{})
+}
+
+func (app *App) switchCollector(collector Collector) {
+	app.collector = collector
+	app.cpuHistory = internal.NewHistory(historySize)
+	app.memHistory = internal.NewHistory(historySize)
+	app.netBaseline = nil // re-captured from the new source
+	app.diskHistory = nil
+	app.graphSamples = nil
+	app.peaks = newPeaks(time.Now())
+	app.prevProcesses, app.startedProcs, app.exitedProcs = nil, nil, nil
+	app.notableProcesses = nil
+	app.refresh()
+}
+
+func (app *App) displayHostsView() {
+	fmt.Fprintf(&app.frame, "%s%s Hosts%s\n", app.colorize("", ColorBold+ColorBlue), app.icon("🗄️"), app.colorize("", ColorReset))
+	fmt.Fprintln(&app.frame, app.colorize(strings.Repeat("─", 80), ColorDim))
+	fmt.Fprintf(&app.frame, "  %-30s %8s %8s %8s  %s\n", "HOST", "CPU", "MEM", "DISK", "STATUS")
+
+	if len(app.hostSummaries) == 0 {
+		fmt.Fprintln(&app.frame, app.colorize("  Waiting for the first poll...", ColorDim))
+	}
+	for i, host := range app.hostSummaries {
+		marker := "  "
+		name := app.truncateString(host.Host, 30)
+		if i == app.hostSelected {
+			marker = app.colorize("> ", ColorBold+ColorYellow)
+			name = app.colorize(fmt.Sprintf("%-30s", name), ColorBold)
+		} else {
+			name = fmt.Sprintf("%-30s", name)
+		}
+
+		if host.Err != nil {
+			fmt.Fprintf(&app.frame, "%s%s %8s %8s %8s  %s\n", marker, name, "-", "-", "-",
+				app.colorize(app.truncateString("DOWN: "+host.Err.Error(), 30), ColorRed))
+			continue
+		}
+
+		status := "OK"
+		switch worst := host.Worst(); {
+		case worst > app.critThreshold:
+			status = "CRIT"
+		case worst > app.warnThreshold:
+			status = "WARN"
+		}
+		column := func(section string, value float64) string {
+			if host.failed(section) {
+				return fmt.Sprintf("%8s", "-")
+			}
+			return app.colorize(fmt.Sprintf("%7.1f%%", value), app.getUsageColor(value))
+		}
+		status = app.colorize(status, ColorBold+app.getUsageColor(host.Worst()))
+		if len(host.Failed) > 0 {
+			status += app.colorize(" (no "+strings.Join(host.Failed, ", ")+" stats)", ColorYellow)
+		}
+		fmt.Fprintf(&app.frame, "%s%s %s %s %s  %s\n", marker, name,
+			column("cpu", host.CPU), column("memory", host.Memory), column("disk", host.Disk), status)
+	}
+
+	fmt.Fprintln(&app.frame)
+	fmt.Fprintln(&app.frame, app.colorize("  ↑/↓ select a host, Enter opens its views, Esc returns here", ColorDim))
+}
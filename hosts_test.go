@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+func TestSummarizeHost(t *testing.T) {
+	stats := &internal.SystemStats{
+		CPU:    internal.CPUInfo{Usage: 35},
+		Memory: internal.MemoryInfo{UsedPercent: 50},
+		Disk:   []internal.DiskInfo{{Mountpoint: "/", UsedPercent: 40}, {Mountpoint: "/var", UsedPercent: 91}},
+	}
+	summary := summarizeHost("web1", stats)
+	if summary.CPU != 35 || summary.Memory != 50 || summary.Disk != 91 || len(summary.Failed) != 0 {
+		t.Errorf("summary %+v", summary)
+	}
+	if summary.Worst() != 91 {
+		t.Errorf("Worst() = %v, want 91", summary.Worst())
+	}
+}
+
+func TestSummarizeHostFailedSections(t *testing.T) {
+	// A failed section is zero in the agent's JSON, and must not read as
+	// an idle CPU or an empty disk
+	stats := &internal.SystemStats{
+		Memory: internal.MemoryInfo{UsedPercent: 70},
+		Disk:   []internal.DiskInfo{{Mountpoint: "/", UsedPercent: 99}},
+		Errors: map[string]string{"cpu": "no /proc/stat", "disk": "statfs timed out"},
+	}
+	summary := summarizeHost("db1", stats)
+	if want := []string{"cpu", "disk"}; !slices.Equal(summary.Failed, want) {
+		t.Errorf("Failed = %v, want %v", summary.Failed, want)
+	}
+	if summary.Disk != 0 || summary.Memory != 70 || summary.Worst() != 70 {
+		t.Errorf("summary %+v", summary)
+	}
+}
+
+func TestSnapshotUsageSkipsFailedSections(t *testing.T) {
+	snap := snapshot{System: &internal.SystemStats{
+		CPU:    internal.CPUInfo{Usage: 20},
+		Memory: internal.MemoryInfo{UsedPercent: 30},
+	}}
+	if cpu, mem, err := snap.usage(); err != nil || cpu != 20 || mem != 30 {
+		t.Errorf("usage() = %v, %v, %v", cpu, mem, err)
+	}
+	snap.System.Errors = map[string]string{"cpu": "no /proc/stat"}
+	if _, _, err := snap.usage(); err == nil {
+		t.Error("usage() of a sample without CPU stats succeeded")
+	}
+}
+
+// fakeAgent answers /stats with a fixed sample after delay
+func fakeAgent(t *testing.T, cpu float64, delay time.Duration) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		json.NewEncoder(w).Encode(snapshot{System: &internal.SystemStats{CPU: internal.CPUInfo{Usage: cpu}}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Run with -race: the poll runs on its own goroutine and must hand its
+// results over through Do
+func TestPollHostsInBackground(t *testing.T) {
+	fast := fakeAgent(t, 10, 0)
+	slow := fakeAgent(t, 20, 300*time.Millisecond)
+
+	app, cancel := newLoopApp()
+	app.hosts = []string{fast.URL, slow.URL}
+	done := startLoop(app, cancel, nil)
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	var started time.Time
+	var polling bool
+	app.Do(func() {
+		started = time.Now()
+		app.pollHosts()
+		app.pollHosts() // already polling: skipped
+		polling = app.pollingHosts
+	})
+	if elapsed := time.Since(started); elapsed > 200*time.Millisecond {
+		t.Errorf("pollHosts held up the main loop for %v", elapsed)
+	}
+	if !polling {
+		t.Fatal("pollHosts did not start a poll")
+	}
+
+	var summaries []HostSummary
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		app.Do(func() {
+			if !app.pollingHosts {
+				summaries = app.hostSummaries
+			}
+		})
+		if summaries != nil {
+			break
+		}
+	}
+	if len(summaries) != 2 || summaries[0].CPU != 10 || summaries[1].CPU != 20 {
+		t.Errorf("summaries %+v, want both hosts in the order given", summaries)
+	}
+}
@@ -0,0 +1,52 @@
+// influx.go - InfluxDB UDP output
+package main
+
+import (
+	"net"
+	"strings"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// influxMaxPacket keeps datagrams below a typical MTU
+const influxMaxPacket = 1400
+
+// InfluxSender ships line protocol to an InfluxDB UDP listener
+type InfluxSender struct {
+	conn net.Conn
+}
+
+// NewInfluxSender prepares a UDP socket for addr (host:port)
+func NewInfluxSender(addr string) (*InfluxSender, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &InfluxSender{conn: conn}, nil
+}
+
+// Send formats the stats and writes them, batching lines into datagrams
+func (s *InfluxSender) Send(stats *internal.SystemStats, netStats *internal.NetworkStats) error {
+	var batch strings.Builder
+	for _, line := range internal.FormatInfluxLine(stats, netStats) {
+		if batch.Len() > 0 && batch.Len()+len(line)+1 > influxMaxPacket {
+			if _, err := s.conn.Write([]byte(batch.String())); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		batch.WriteString(line)
+		batch.WriteByte('\n')
+	}
+
+	if batch.Len() > 0 {
+		_, err := s.conn.Write([]byte(batch.String()))
+		return err
+	}
+	return nil
+}
+
+// Close releases the socket
+func (s *InfluxSender) Close() error {
+	return s.conn.Close()
+}
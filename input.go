@@ -0,0 +1,167 @@
+// input.go - Keyboard input decoding
+package main
+
+import (
+	"bufio"
+	"io"
+)
+
+// Key identifies a keyboard event. Printable keys are KeyRune events that
+// carry the character; the others are decoded from control bytes and
+// terminal escape sequences.
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPageUp
+	KeyPageDown
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyTab
+	KeyShiftTab
+)
+
+// KeyEvent is one key press read from the terminal
+type KeyEvent struct {
+	Key  Key
+	Rune rune // Set for KeyRune
+}
+
+// handleKeyboardInput sends decoded key presses until input ends, then
+// closes the channel
+func handleKeyboardInput(input io.Reader, events chan<- KeyEvent) {
+	reader := bufio.NewReader(input)
+	for {
+		event, err := readKey(reader)
+		if err != nil {
+			close(events)
+			return
+		}
+		if event != nil {
+			events <- *event
+		}
+	}
+}
+
+// collect runs fn, a collection that may take a while, reading the
+// keyboard meanwhile: a q pressed before any other key cancels app.ctx, so
+// the collection stops early and the main loop quits. Quitting that needs
+// confirming is left to the prompt. The keys read are kept in
+// app.pendingKeys for the main loop, which handles them before any others.
+func (app *App) collect(fn func()) {
+	// A prompt reads the keys itself
+	if app.input == nil || app.cancel == nil || app.collecting || app.prompt != "" {
+		fn()
+		return
+	}
+	app.collecting = true
+	defer func() { app.collecting = false }()
+
+	cancel := app.cancel
+	cancelOnQuit := !app.confirmQuit || app.activeCapture() == ""
+	done := make(chan struct{})
+	read := make(chan []KeyEvent)
+	go func(input <-chan KeyEvent) {
+		var keys []KeyEvent
+		for {
+			select {
+			case <-done:
+				read <- keys
+				return
+			case key, ok := <-input:
+				if !ok {
+					input = nil // the main loop notices the end of input itself
+					continue
+				}
+				if len(keys) == 0 && cancelOnQuit && isQuitKey(key) {
+					cancel()
+				}
+				keys = append(keys, key)
+			}
+		}
+	}(app.input)
+
+	defer func() {
+		close(done)
+		app.pendingKeys = append(app.pendingKeys, <-read...)
+	}()
+	fn()
+}
+
+// isQuitKey reports whether key is q, which quits from any view
+func isQuitKey(key KeyEvent) bool {
+	return key.Key == KeyRune && (key.Rune == 'q' || key.Rune == 'Q')
+}
+
+// readKey reads one key press. A nil event means the bytes read were an
+// escape sequence this decoder does not know, and were dropped.
+func readKey(reader *bufio.Reader) (*KeyEvent, error) {
+	char, _, err := reader.ReadRune()
+	if err != nil {
+		return nil, err
+	}
+	switch char {
+	case 0x1b:
+		return readEscapeSequence(reader), nil
+	case '\r', '\n':
+		return &KeyEvent{Key: KeyEnter}, nil
+	case '\t':
+		return &KeyEvent{Key: KeyTab}, nil
+	case 0x7f, '\b':
+		return &KeyEvent{Key: KeyBackspace}, nil
+	}
+	return &KeyEvent{Key: KeyRune, Rune: char}, nil
+}
+
+// readEscapeSequence decodes the key after an ESC. A terminal sends the
+// bytes of a control sequence such as an arrow key (ESC [ A) together, so
+// an ESC with nothing else buffered is the Esc key itself.
+func readEscapeSequence(reader *bufio.Reader) *KeyEvent {
+	if reader.Buffered() == 0 {
+		return &KeyEvent{Key: KeyEscape}
+	}
+	next, _ := reader.Peek(1)
+	if next[0] != '[' && next[0] != 'O' {
+		return &KeyEvent{Key: KeyEscape}
+	}
+	reader.ReadByte() // '[' (CSI) or 'O' (SS3, arrows in application mode)
+
+	// Parameter bytes up to the final byte in 0x40-0x7e
+	var param []byte
+	for reader.Buffered() > 0 {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil
+		}
+		if b < 0x40 || b > 0x7e {
+			param = append(param, b)
+			continue
+		}
+		switch b {
+		case 'A':
+			return &KeyEvent{Key: KeyUp}
+		case 'B':
+			return &KeyEvent{Key: KeyDown}
+		case 'C':
+			return &KeyEvent{Key: KeyRight}
+		case 'D':
+			return &KeyEvent{Key: KeyLeft}
+		case 'Z':
+			return &KeyEvent{Key: KeyShiftTab}
+		case '~':
+			switch string(param) {
+			case "5":
+				return &KeyEvent{Key: KeyPageUp}
+			case "6":
+				return &KeyEvent{Key: KeyPageDown}
+			}
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+//go:build unix
+
+package main
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestKeyboardInputClosesAtEOF(t *testing.T) {
+	events := make(chan KeyEvent, 4)
+	handleKeyboardInput(strings.NewReader("q\t"), events)
+
+	var got []KeyEvent
+	for event := range events {
+		got = append(got, event)
+	}
+	want := []KeyEvent{{Key: KeyRune, Rune: 'q'}, {Key: KeyTab}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("events %v, want %v then a closed channel", got, want)
+	}
+}
+
+// cpuTime returns the user and system CPU time this process has used
+func cpuTime(t *testing.T) time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		t.Skipf("getrusage: %v", err)
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
+
+// After stdin ends the loop must go on serving refreshes and Do, and sit
+// idle in between rather than spinning on the closed channel
+func TestRunIdlesAfterInputCloses(t *testing.T) {
+	app, cancel := newLoopApp()
+	input := make(chan KeyEvent)
+	close(input)
+	done := startLoop(app, cancel, input)
+
+	// Once Do runs, the loop has been through its select at least once
+	if !app.Do(func() {}) {
+		t.Fatal("loop exited when input closed")
+	}
+	const window = 300 * time.Millisecond
+	before := cpuTime(t)
+	time.Sleep(window)
+	if used := cpuTime(t) - before; used > window/2 {
+		t.Errorf("loop used %v of CPU in %v with no input, want it idle", used, window)
+	}
+
+	select {
+	case <-done:
+		t.Fatal("loop exited when input closed")
+	default:
+	}
+	if !app.Do(func() {}) {
+		t.Error("Do failed after input closed")
+	}
+	cancel()
+	<-done
+}
+
+// collectUntil stands in for a slow collection: it runs until ctx is
+// cancelled or the test gives up on it, and reports which came first
+func collectUntil(app *App, keys chan<- KeyEvent, send []KeyEvent) (cancelled bool) {
+	app.collect(func() {
+		for _, key := range send {
+			keys <- key
+		}
+		select {
+		case <-app.ctx.Done():
+			cancelled = true
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+	return cancelled
+}
+
+func TestQuitCancelsCollection(t *testing.T) {
+	app, cancel := newLoopApp()
+	defer cancel()
+	keys := make(chan KeyEvent)
+	app.cancel, app.input = cancel, keys
+
+	if !collectUntil(app, keys, []KeyEvent{{Key: KeyRune, Rune: 'q'}}) {
+		t.Fatal("q did not cancel the collection")
+	}
+	if len(app.pendingKeys) != 1 || !isQuitKey(app.pendingKeys[0]) {
+		t.Errorf("pending keys %v, want the q for the main loop", app.pendingKeys)
+	}
+
+	// The main loop then quits on the q, before waiting for anything else
+	done := startLoop(app, cancel, keys)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("main loop did not quit on the pending q")
+	}
+}
+
+func TestCollectKeepsOtherKeys(t *testing.T) {
+	app, cancel := newLoopApp()
+	defer cancel()
+	keys := make(chan KeyEvent)
+	app.cancel, app.input = cancel, keys
+
+	// A q typed after "/" is part of a filter, not a quit
+	typed := []KeyEvent{{Key: KeyRune, Rune: '/'}, {Key: KeyRune, Rune: 'q'}, {Key: KeyEnter}}
+	if collectUntil(app, keys, typed) {
+		t.Error("a q after another key cancelled the collection")
+	}
+	if len(app.pendingKeys) != len(typed) {
+		t.Fatalf("pending keys %v, want %v", app.pendingKeys, typed)
+	}
+	for i := range typed {
+		if app.pendingKeys[i] != typed[i] {
+			t.Errorf("pending key %d is %v, want %v", i, app.pendingKeys[i], typed[i])
+		}
+	}
+}
+
+func TestQuitDuringCaptureWaitsForConfirmation(t *testing.T) {
+	app, cancel := newLoopApp()
+	defer cancel()
+	keys := make(chan KeyEvent)
+	app.cancel, app.input = cancel, keys
+	app.confirmQuit, app.logToFile = true, true
+
+	if collectUntil(app, keys, []KeyEvent{{Key: KeyRune, Rune: 'q'}}) {
+		t.Error("q cancelled the collection while logging with -confirm-quit")
+	}
+	if len(app.pendingKeys) != 1 {
+		t.Errorf("pending keys %v, want the q to be asked about", app.pendingKeys)
+	}
+}
@@ -0,0 +1,78 @@
+// internal/chart.go
+package internal
+
+import (
+	"math"
+	"strings"
+)
+
+// brailleDots are the bits of the dots in a braille cell, indexed by
+// column (0 left, 1 right) and row (0 top to 3 bottom)
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// BrailleChart renders the last 2*width values of series as a line chart
+// of height rows, each cell holding a 2x4 grid of braille dots. Values are
+// treated as percentages like Sparkline: 0 maps to the bottom and 100 (or
+// the largest value, if higher) to the top. NaN values are gaps, and a
+// shorter series is right-aligned so the newest sample sits in the last
+// column.
+func BrailleChart(series []float64, width, height int) []string {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	points := 2 * width
+	if len(series) > points {
+		series = series[len(series)-points:]
+	}
+	offset := points - len(series)
+
+	top := 100.0
+	for _, v := range series {
+		if v > top {
+			top = v
+		}
+	}
+
+	cells := make([][]rune, height)
+	for row := range cells {
+		cells[row] = make([]rune, width)
+	}
+	plot := func(x, y int) {
+		// y counts dots up from the bottom
+		row := height - 1 - y/4
+		cells[row][x/2] |= brailleDots[x%2][3-y%4]
+	}
+
+	dots := 4 * height
+	previous := -1
+	for i, v := range series {
+		if math.IsNaN(v) {
+			previous = -1
+			continue
+		}
+		y := int(math.Round(max(v, 0) / top * float64(dots-1)))
+		x := offset + i
+		plot(x, y)
+		// Join to the previous point with a vertical run so steep changes
+		// read as a line rather than scattered dots
+		if previous >= 0 {
+			for step := min(previous, y) + 1; step < max(previous, y); step++ {
+				plot(x, step)
+			}
+		}
+		previous = y
+	}
+
+	lines := make([]string, height)
+	for row, cellRow := range cells {
+		var b strings.Builder
+		for _, dots := range cellRow {
+			b.WriteRune(0x2800 + dots)
+		}
+		lines[row] = b.String()
+	}
+	return lines
+}
@@ -0,0 +1,110 @@
+// internal/gpu/gpu.go
+package gpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GPUInfo holds utilization and memory information for a single GPU
+type GPUInfo struct {
+	Index              int     `json:"index"`
+	Name               string  `json:"name"`
+	UtilizationPercent float64 `json:"utilization_percent"`
+	MemoryUsedMB       uint64  `json:"memory_used_mb"`
+	MemoryTotalMB      uint64  `json:"memory_total_mb"`
+	TemperatureC       float64 `json:"temperature_c"`
+}
+
+// MemoryUsedPercent returns the share of GPU memory in use
+func (g GPUInfo) MemoryUsedPercent() float64 {
+	if g.MemoryTotalMB == 0 {
+		return 0
+	}
+	return float64(g.MemoryUsedMB) / float64(g.MemoryTotalMB) * 100
+}
+
+// queryFields is the column order requested from nvidia-smi
+const queryFields = "index,name,utilization.gpu,memory.used,memory.total,temperature.gpu"
+
+// queryTimeout bounds a single nvidia-smi invocation
+const queryTimeout = 5 * time.Second
+
+// GetGPUInfo queries NVIDIA GPUs through nvidia-smi. It returns no GPUs and
+// no error when nvidia-smi is not installed. Lines that cannot be parsed are
+// logged and skipped.
+func GetGPUInfo(ctx context.Context) ([]GPUInfo, error) {
+	path, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path,
+		"--query-gpu="+queryFields, "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil, fmt.Errorf("nvidia-smi failed: %w", err)
+	}
+
+	return parseNvidiaSMI(string(output)), nil
+}
+
+// parseNvidiaSMI parses the CSV output of an nvidia-smi query for queryFields
+func parseNvidiaSMI(output string) []GPUInfo {
+	var gpus []GPUInfo
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		if len(fields) != 6 {
+			log.Printf("gpu: unexpected nvidia-smi line %q", line)
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			log.Printf("gpu: invalid index in nvidia-smi line %q: %v", line, err)
+			continue
+		}
+
+		gpus = append(gpus, GPUInfo{
+			Index:              index,
+			Name:               fields[1],
+			UtilizationPercent: parseFloat(fields[2]),
+			MemoryUsedMB:       uint64(parseFloat(fields[3])),
+			MemoryTotalMB:      uint64(parseFloat(fields[4])),
+			TemperatureC:       parseFloat(fields[5]),
+		})
+	}
+	return gpus
+}
+
+// parseFloat parses a numeric nvidia-smi field. Fields the driver does not
+// support ("[N/A]", "[Not Supported]") are reported as zero.
+func parseFloat(field string) float64 {
+	if strings.HasPrefix(field, "[") {
+		return 0
+	}
+	value, err := strconv.ParseFloat(field, 64)
+	if err != nil {
+		log.Printf("gpu: invalid nvidia-smi value %q: %v", field, err)
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,107 @@
+// internal/history.go
+package internal
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// History is a fixed-size ring buffer of samples, oldest first
+type History struct {
+	samples []float64
+	next    int
+	full    bool
+}
+
+// NewHistory creates a history holding up to size samples
+func NewHistory(size int) *History {
+	return &History{samples: make([]float64, size)}
+}
+
+// Add records a sample, overwriting the oldest once the buffer is full
+func (h *History) Add(value float64) {
+	if len(h.samples) == 0 {
+		return
+	}
+	h.samples[h.next] = value
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Values returns the recorded samples from oldest to newest
+func (h *History) Values() []float64 {
+	if !h.full {
+		return append([]float64(nil), h.samples[:h.next]...)
+	}
+	return append(append([]float64(nil), h.samples[h.next:]...), h.samples[:h.next]...)
+}
+
+// sparkBlocks are the eighth-height block characters used by Sparkline
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders the last width values as block characters. Values are
+// treated as percentages: 0 maps to the lowest block and 100 (or the largest
+// value, if higher) to the tallest. Shorter series are left-padded with
+// spaces so the newest sample always sits in the last column.
+func Sparkline(values []float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	max := 100.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width-len(values)))
+	for _, v := range values {
+		if v < 0 {
+			v = 0
+		}
+		b.WriteRune(sparkBlocks[int(v/max*float64(len(sparkBlocks)-1)+0.5)])
+	}
+	return b.String()
+}
+
+// Last CPU reading of SampleUsage, reused within MinSampleInterval
+var (
+	lastUsageRead time.Time
+	lastUsageCPU  float64
+)
+
+// SampleUsage returns CPU usage averaged since the previous call and the
+// current memory usage, both as percentages. Unlike GetSystemStats it does
+// not block for a CPU measurement window, so it is cheap to call every refresh.
+// Calls within MinSampleInterval of the last CPU reading return it again.
+func SampleUsage(ctx context.Context) (cpuPercent, memPercent float64, err error) {
+	if time.Since(lastUsageRead) < MinSampleInterval {
+		cpuPercent = lastUsageCPU
+	} else {
+		percentages, err := cpu.PercentWithContext(ctx, 0, false)
+		if err != nil {
+			return 0, 0, err
+		}
+		if len(percentages) > 0 {
+			cpuPercent = percentages[0]
+		}
+		lastUsageRead, lastUsageCPU = time.Now(), cpuPercent
+	}
+
+	vmem, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return cpuPercent, vmem.UsedPercent, nil
+}
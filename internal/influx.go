@@ -0,0 +1,80 @@
+// internal/influx.go
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatInfluxLine renders system and network stats as InfluxDB line
+// protocol, one measurement per line with a nanosecond timestamp. Either
+// argument may be nil. Disks and interfaces are tagged by name. Sections
+// listed in stats.Errors are left out rather than written as zeros.
+func FormatInfluxLine(stats *SystemStats, net *NetworkStats) []string {
+	var lines []string
+
+	if stats != nil {
+		host := escapeInfluxTag(stats.Host.Hostname)
+		ts := stats.Timestamp.UnixNano()
+
+		if _, failed := stats.Errors["cpu"]; !failed {
+			var loadFields string
+			if avg := stats.CPU.Load; avg != nil {
+				loadFields = fmt.Sprintf(",load1=%s,load5=%s,load15=%s",
+					formatInfluxFloat(avg.Load1), formatInfluxFloat(avg.Load5), formatInfluxFloat(avg.Load15))
+			}
+			lines = append(lines, fmt.Sprintf("cpu,host=%s usage=%s,cores=%di%s %d",
+				host, formatInfluxFloat(stats.CPU.Usage), stats.CPU.Cores, loadFields, ts))
+		}
+		if _, failed := stats.Errors["memory"]; !failed {
+			lines = append(lines, fmt.Sprintf("mem,host=%s total=%di,used=%di,available=%di,free=%di,used_percent=%s %d",
+				host, stats.Memory.Total, stats.Memory.Used, stats.Memory.Available, stats.Memory.Free,
+				formatInfluxFloat(stats.Memory.UsedPercent), ts))
+		}
+		if _, failed := stats.Errors["disk"]; !failed {
+			for _, disk := range stats.Disk {
+				lines = append(lines, fmt.Sprintf(
+					"disk,host=%s,device=%s,path=%s,fstype=%s total=%di,used=%di,free=%di,used_percent=%s %d",
+					host, escapeInfluxTag(disk.Device), escapeInfluxTag(disk.Mountpoint), escapeInfluxTag(disk.Fstype),
+					disk.Total, disk.Used, disk.Free, formatInfluxFloat(disk.UsedPercent), ts))
+			}
+		}
+	}
+
+	if net != nil {
+		host := ""
+		if stats != nil {
+			host = escapeInfluxTag(stats.Host.Hostname)
+		}
+		ts := net.Timestamp.UnixNano()
+
+		for _, iface := range net.Interfaces {
+			tags := "interface=" + escapeInfluxTag(iface.Name)
+			if host != "" {
+				tags = "host=" + host + "," + tags
+			}
+			lines = append(lines, fmt.Sprintf(
+				"net,%s bytes_sent=%di,bytes_recv=%di,packets_sent=%di,packets_recv=%di,errin=%di,errout=%di,dropin=%di,dropout=%di %d",
+				tags, iface.BytesSent, iface.BytesRecv, iface.PacketsSent, iface.PacketsRecv,
+				iface.Errin, iface.Errout, iface.Dropin, iface.Dropout, ts))
+		}
+	}
+
+	return lines
+}
+
+// influxTagEscaper escapes the characters line protocol reserves in tag
+// keys and values
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+func escapeInfluxTag(value string) string {
+	if value == "" {
+		return "unknown" // Empty tag values are not allowed
+	}
+	return influxTagEscaper.Replace(value)
+}
+
+func formatInfluxFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
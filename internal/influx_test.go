@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatInfluxLine(t *testing.T) {
+	stats := &SystemStats{
+		CPU:       CPUInfo{Usage: 12.5, Cores: 4, Load: &LoadAvg{Load1: 0.5, Load5: 1.25, Load15: 2}},
+		Memory:    MemoryInfo{Total: 1000, Used: 400, Available: 600, Free: 500, UsedPercent: 40},
+		Disk:      []DiskInfo{{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4", Total: 100, Used: 25, Free: 75, UsedPercent: 25}},
+		Host:      HostInfo{Hostname: "web 1"},
+		Timestamp: time.Unix(0, 42),
+	}
+	net := &NetworkStats{
+		Interfaces: []NetworkInterface{{Name: "eth0", BytesSent: 1, BytesRecv: 2}},
+		Timestamp:  time.Unix(0, 43),
+	}
+	want := []string{
+		`cpu,host=web\ 1 usage=12.5,cores=4i,load1=0.5,load5=1.25,load15=2 42`,
+		`mem,host=web\ 1 total=1000i,used=400i,available=600i,free=500i,used_percent=40 42`,
+		`disk,host=web\ 1,device=/dev/sda1,path=/,fstype=ext4 total=100i,used=25i,free=75i,used_percent=25 42`,
+		`net,host=web\ 1,interface=eth0 bytes_sent=1i,bytes_recv=2i,packets_sent=0i,packets_recv=0i,errin=0i,errout=0i,dropin=0i,dropout=0i 43`,
+	}
+	if got := FormatInfluxLine(stats, net); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("lines:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFormatInfluxLineWithoutLoad(t *testing.T) {
+	stats := &SystemStats{CPU: CPUInfo{Usage: 3, Cores: 2}, Host: HostInfo{Hostname: "win"}, Timestamp: time.Unix(0, 7)}
+	if got, want := FormatInfluxLine(stats, nil)[0], "cpu,host=win usage=3,cores=2i 7"; got != want {
+		t.Errorf("cpu line %q, want %q", got, want)
+	}
+}
+
+func TestFormatInfluxLineSkipsFailedSections(t *testing.T) {
+	stats := &SystemStats{
+		Disk:   []DiskInfo{{Device: "/dev/sda1", Mountpoint: "/"}},
+		Host:   HostInfo{Hostname: "db"},
+		Errors: map[string]string{"cpu": "permission denied", "disk": "timeout"},
+	}
+	lines := FormatInfluxLine(stats, nil)
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "mem,host=db ") {
+		t.Errorf("lines %q, want only the memory line", lines)
+	}
+
+	stats.Errors = map[string]string{"memory": "no /proc/meminfo"}
+	for _, line := range FormatInfluxLine(stats, nil) {
+		if strings.HasPrefix(line, "mem,") {
+			t.Errorf("failed memory section written: %q", line)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// internal/monitor.go - Aliases for the collectors promoted to pkg/monitor
+package internal
+
+import "github.com/imunderthetree/sysmon/pkg/monitor"
+
+// The collection types and functions live in sysmon/pkg/monitor, the
+// public API. These aliases keep the existing internal names working for
+// the interfaces and the remaining internal helpers.
+
+type (
+	SystemStats           = monitor.SystemStats
+	CPUInfo               = monitor.CPUInfo
+	LoadAvg               = monitor.LoadAvg
+	MemoryInfo            = monitor.MemoryInfo
+	DiskInfo              = monitor.DiskInfo
+	HostInfo              = monitor.HostInfo
+	TempUnit              = monitor.TempUnit
+	ProcessInfo           = monitor.ProcessInfo
+	ProcessStats          = monitor.ProcessStats
+	ProcessGroup          = monitor.ProcessGroup
+	ProcessWatcher        = monitor.ProcessWatcher
+	ProcessDetails        = monitor.ProcessDetails
+	ProcessNetwork        = monitor.ProcessNetwork
+	NetworkInterface      = monitor.NetworkInterface
+	NetworkStats          = monitor.NetworkStats
+	NetworkSpeed          = monitor.NetworkSpeed
+	DiskIOSpeed           = monitor.DiskIOSpeed
+	ConnectionBreakdown   = monitor.ConnectionBreakdown
+	ConnectionStateCounts = monitor.ConnectionStateCounts
+	ListenPort            = monitor.ListenPort
+	Connection            = monitor.Connection
+	TrafficBaseline       = monitor.TrafficBaseline
+	InterfaceFilter       = monitor.InterfaceFilter
+	ClockWatch            = monitor.ClockWatch
+)
+
+const (
+	Celsius        = monitor.Celsius
+	Fahrenheit     = monitor.Fahrenheit
+	DefaultFDLimit = monitor.DefaultFDLimit
+	MinNice        = monitor.MinNice
+	MaxNice        = monitor.MaxNice
+
+	DefaultInterfaceFilter = monitor.DefaultInterfaceFilter
+	DefaultTopProcesses    = monitor.DefaultTopProcesses
+	DefaultDiskTimeout     = monitor.DefaultDiskTimeout
+	MinSampleInterval      = monitor.MinSampleInterval
+	ClockJumpThreshold     = monitor.ClockJumpThreshold
+)
+
+var (
+	DefaultExcludedFstypes       = monitor.DefaultExcludedFstypes
+	ErrProcessExited             = monitor.ErrProcessExited
+	ErrDiskTimeout               = monitor.ErrDiskTimeout
+	ErrMountUsersUnsupported     = monitor.ErrMountUsersUnsupported
+	ErrMountUsersPartial         = monitor.ErrMountUsersPartial
+	ErrProcessNetworkUnsupported = monitor.ErrProcessNetworkUnsupported
+	ErrNiceUnsupported           = monitor.ErrNiceUnsupported
+
+	GetSystemStats            = monitor.GetSystemStats
+	GetProcessStats           = monitor.GetProcessStats
+	GetNetworkStats           = monitor.GetNetworkStats
+	GetNetworkSpeeds          = monitor.GetNetworkSpeeds
+	GetDiskIOSpeeds           = monitor.GetDiskIOSpeeds
+	GetTopNetworkInterfaces   = monitor.GetTopNetworkInterfaces
+	GetListeningPorts         = monitor.GetListeningPorts
+	GetEstablishedConnections = monitor.GetEstablishedConnections
+	GetProcessNetwork         = monitor.GetProcessNetwork
+	GetProcessDetails         = monitor.GetProcessDetails
+	ProcessesUsingMount       = monitor.ProcessesUsingMount
+	NewProcessWatcher         = monitor.NewProcessWatcher
+	NewTrafficBaseline        = monitor.NewTrafficBaseline
+	AggregateByName           = monitor.AggregateByName
+	FilterProcesses           = monitor.FilterProcesses
+	FilterDisks               = monitor.FilterDisks
+	ParseTempUnit             = monitor.ParseTempUnit
+	ProcessUptime             = monitor.ProcessUptime
+	SetNice                   = monitor.SetNice
+	ParseInterfaceFilter      = monitor.ParseInterfaceFilter
+	SetInterfaceFilter        = monitor.SetInterfaceFilter
+	SetTopProcesses           = monitor.SetTopProcesses
+	SetDiskTimeout            = monitor.SetDiskTimeout
+
+	FormatBytes        = monitor.FormatBytes
+	FormatBytesSI      = monitor.FormatBytesSI
+	FormatNetworkSpeed = monitor.FormatNetworkSpeed
+	FormatNetworkBytes = monitor.FormatNetworkBytes
+	FormatTemperature  = monitor.FormatTemperature
+	FormatUptime       = monitor.FormatUptime
+	FormatBootTime     = monitor.FormatBootTime
+)
@@ -0,0 +1,87 @@
+// internal/proctree.go
+package internal
+
+import "sort"
+
+// ProcessNode is a process together with its child processes
+type ProcessNode struct {
+	Process  ProcessInfo
+	Children []*ProcessNode
+}
+
+// BuildProcessTree arranges procs into a parent/child hierarchy under a
+// synthetic root (PID 0). Processes whose parent is not in procs, because it
+// already exited or is hidden from us, become children of the root, as does
+// anything left unreachable by PID reuse cycles. Children are sorted by PID.
+func BuildProcessTree(procs []ProcessInfo) *ProcessNode {
+	root := &ProcessNode{Process: ProcessInfo{Name: "[root]"}}
+
+	nodes := make(map[int32]*ProcessNode, len(procs))
+	for _, proc := range procs {
+		nodes[proc.PID] = &ProcessNode{Process: proc}
+	}
+
+	for _, proc := range procs {
+		node := nodes[proc.PID]
+		parent, ok := nodes[proc.PPID]
+		if !ok || proc.PPID == proc.PID {
+			parent = root
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	// Re-home processes caught in parent cycles, which are not reachable
+	// from the root and would otherwise be silently dropped
+	reached := make(map[int32]bool, len(nodes))
+	var mark func(n *ProcessNode)
+	mark = func(n *ProcessNode) {
+		for _, child := range n.Children {
+			if !reached[child.Process.PID] {
+				reached[child.Process.PID] = true
+				mark(child)
+			}
+		}
+	}
+	mark(root)
+	for _, proc := range procs {
+		if !reached[proc.PID] {
+			node := nodes[proc.PID]
+			if parent, ok := nodes[proc.PPID]; ok {
+				parent.Children = removeNode(parent.Children, node)
+			}
+			root.Children = append(root.Children, node)
+			reached[proc.PID] = true
+			mark(node)
+		}
+	}
+
+	root.sortChildren()
+	return root
+}
+
+// Descendants returns the number of processes below n
+func (n *ProcessNode) Descendants() int {
+	count := len(n.Children)
+	for _, child := range n.Children {
+		count += child.Descendants()
+	}
+	return count
+}
+
+func (n *ProcessNode) sortChildren() {
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Process.PID < n.Children[j].Process.PID
+	})
+	for _, child := range n.Children {
+		child.sortChildren()
+	}
+}
+
+func removeNode(nodes []*ProcessNode, target *ProcessNode) []*ProcessNode {
+	for i, node := range nodes {
+		if node == target {
+			return append(nodes[:i], nodes[i+1:]...)
+		}
+	}
+	return nodes
+}
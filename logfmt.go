@@ -0,0 +1,73 @@
+// logfmt.go - logfmt log entries (-log-format logfmt)
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// LogFormat selects how log entries are written
+type LogFormat int
+
+const (
+	// LogJSON writes the full entry as one JSON object per line (NDJSON)
+	LogJSON LogFormat = iota
+	// LogLogfmt writes the headline metrics as key=value pairs
+	LogLogfmt
+)
+
+// ParseLogFormat accepts "json" or "logfmt", case-insensitively
+func ParseLogFormat(value string) (LogFormat, error) {
+	switch strings.ToLower(value) {
+	case "json":
+		return LogJSON, nil
+	case "logfmt":
+		return LogLogfmt, nil
+	}
+	return LogJSON, fmt.Errorf("unknown log format %q", value)
+}
+
+// formatLogfmt renders an entry from newLogEntry as one logfmt line:
+//
+//	ts=2024-05-01T12:00:00Z cpu=23.1 mem=61.0 swap=0.0 disk_root=45.0 procs=312 ...
+//
+// Percentages have one decimal, byte counters are raw. Filesystems of the
+// excluded types are left out, as in the disk views.
+func formatLogfmt(entry map[string]interface{}, excluded []string) string {
+	var b strings.Builder
+	field := func(key string, value interface{}) {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%s=%v", key, value)
+	}
+	percent := func(v float64) string { return fmt.Sprintf("%.1f", v) }
+
+	field("ts", entry["timestamp"])
+	if stats, ok := entry["system"].(*internal.SystemStats); ok && stats != nil {
+		field("cpu", percent(stats.CPU.Usage))
+		field("mem", percent(stats.Memory.UsedPercent))
+		field("swap", percent(stats.Memory.SwapUsedPercent))
+		for _, disk := range internal.FilterDisks(stats.Disk, excluded) {
+			if !disk.Unresponsive {
+				field(diskLevel(disk).Name, percent(disk.UsedPercent))
+			}
+		}
+	}
+	if procStats, ok := entry["processes"].(*internal.ProcessStats); ok && procStats != nil {
+		field("procs", procStats.TotalProcesses)
+		field("running", procStats.RunningProcs)
+		field("zombie", procStats.ZombieProcs)
+	}
+	if netStats, ok := entry["network"].(*internal.NetworkStats); ok && netStats != nil {
+		field("net_sent", netStats.TotalSent)
+		field("net_recv", netStats.TotalRecv)
+		field("conns", netStats.Connections)
+	}
+	if jump, ok := entry["clock_jump_seconds"].(float64); ok {
+		field("clock_jump", fmt.Sprintf("%.0f", jump))
+	}
+	return b.String()
+}
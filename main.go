@@ -1,17 +1,32 @@
-// main.go - Enhanced System Monitor v1.0
+// main.go - Enhanced System Monitor
 package main
 
 import (
-	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
-	"sysmon/internal"
+	"syscall"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/imunderthetree/sysmon/internal"
+	"github.com/imunderthetree/sysmon/internal/gpu"
+	"golang.org/x/term"
 )
 
 // ViewType represents different monitoring views
@@ -23,6 +38,44 @@ const (
 	ViewNetwork
 	ViewDisks
 	ViewSystem
+	ViewProcess // Single watched process, only available with -pid
+	ViewGraph
+)
+
+// viewNames are the tab titles, indexed by ViewType
+var viewNames = []string{"Overview", "Processes", "Network", "Disks", "System", "Process", "Graph"}
+
+// viewFlagNames maps the view names accepted by -view-refresh to views
+var viewFlagNames = map[string]ViewType{
+	"overview":  ViewOverview,
+	"processes": ViewProcesses,
+	"network":   ViewNetwork,
+	"disks":     ViewDisks,
+	"system":    ViewSystem,
+	"process":   ViewProcess,
+	"graph":     ViewGraph,
+}
+
+// Bounds of the refresh interval, also used for +/- adjustments. The
+// minimum must stay at or above internal.MinSampleInterval, or rate-based
+// metrics would repeat their last reading between refreshes.
+const (
+	minRefreshRate = time.Second
+	maxRefreshRate = 10 * time.Second
+)
+
+// maxEnvLines bounds the environment variables listed in the W overlay
+const maxEnvLines = 20
+
+// overlapWarnPercent is the share of the refresh interval collection may
+// take before the footer warns that refreshes run back to back
+const overlapWarnPercent = 80
+
+// With -adaptive-refresh, the interval grows by a second once collection
+// has taken adaptiveSlowPercent of it for adaptiveSlowRefreshes in a row
+const (
+	adaptiveSlowPercent   = 70
+	adaptiveSlowRefreshes = 3
 )
 
 // Color constants for terminal output
@@ -37,25 +90,641 @@ const (
 	ColorWhite  = "\033[37m"
 	ColorBold   = "\033[1m"
 	ColorDim    = "\033[2m"
+	ColorStrike = "\033[9m"
+)
+
+// emojiLabels maps each section header emoji to the ASCII label shown
+// when emojis are disabled
+var emojiLabels = map[string]string{
+	"🖥️": "[SYS]",
+	"🔧":  "[CPU]",
+	"💾":  "[MEM]",
+	"💽":  "[DSK]",
+	"📄":  "[PRC]",
+	"🔥":  "[TOP]",
+	"🌐":  "[NET]",
+	"📊":  "[STA]",
+	"🔗":  "[CON]",
+	"📈":  "[IFC]",
+	"🔌":  "[LSN]",
+	"🎮":  "[GPU]",
+	"📚":  "[HLP]",
+	"🌳":  "[TRE]",
+	"⚠":  "[!]",
+	"🗄️": "[HST]",
+	"📋":  "[SUM]",
+	"🔀":  "[DIF]",
+}
+
+// Usage history kept for the overview sparklines
+const (
+	historySize    = 60 // samples retained per metric
+	sparklineWidth = 16 // samples shown next to each percentage
 )
 
+// Options holds the command line settings for the terminal interface
+type Options struct {
+	ShowAllFilesystems bool
+	ExcludedFstypes    string
+	NetSmoothing       float64
+	LogInterval        time.Duration
+	LogMaxSizeMB       int64
+	LogMaxFiles        int
+	LogCompress        bool
+	LogFormat          string
+	RefreshRate        time.Duration
+	AdaptiveRefresh    bool
+	Stream             bool
+	Serve              string
+	TopN               int
+	OverviewDisks      int
+	GraphWindow        time.Duration
+	User               string
+	Hosts              string
+	Once               bool
+	Format             string
+	InfluxUDP          string
+	TempUnit           string
+	Theme              string
+	BarStyle           string
+	NoEmoji            bool
+	Color              string
+	WatchPID           int
+	ShowSecrets        bool
+	ResolveHosts       bool
+	Safe               bool
+	WarnThreshold      float64
+	CritThreshold      float64
+	ExportPath         string
+	ExportKeep         int
+	ExportFormats      string
+	DiskTimeout        time.Duration
+	ExportDetail       string
+	Record             string
+	Replay             string
+	ReplayLoop         bool
+	NetAlert           string
+	NetAlertSamples    int
+	IfaceFilter        string
+	Notify             bool
+	ConfirmQuit        bool
+	ViewRefresh        string
+	StatePath          string
+	ShowVersion        bool
+}
+
+// registerFlags defines the terminal interface flags on the default flag set.
+// The returned Options are populated once flag.Parse has run.
+func registerFlags() *Options {
+	opts := &Options{}
+	flag.BoolVar(&opts.ShowVersion, "version", false, "Print the version, commit and build date, then exit")
+	flag.BoolVar(&opts.ShowAllFilesystems, "all-fs", false, "Show pseudo/virtual filesystems in disk views")
+	flag.StringVar(&opts.ExcludedFstypes, "exclude-fs", strings.Join(internal.DefaultExcludedFstypes, ","),
+		"Comma-separated filesystem types hidden from disk views")
+	flag.Float64Var(&opts.NetSmoothing, "net-smoothing", 0,
+		"Smooth network speeds with an exponential moving average (alpha between 0 and 1, 0 = off)")
+	flag.DurationVar(&opts.LogInterval, "log-interval", 3*time.Second,
+		"Interval between log entries while logging is enabled, independent of the active view")
+	flag.Int64Var(&opts.LogMaxSizeMB, "log-max-size", 10, "Rotate the log file once it exceeds this many megabytes (0 = never)")
+	flag.IntVar(&opts.LogMaxFiles, "log-max-files", 5, "Number of rotated log files to keep (0 = keep all)")
+	flag.BoolVar(&opts.LogCompress, "log-compress", false, "Gzip rotated log files in the background")
+	flag.StringVar(&opts.LogFormat, "log-format", "json", "Log entry format: json (full NDJSON entries) or logfmt (key=value headline metrics)")
+	flag.DurationVar(&opts.RefreshRate, "refresh", 3*time.Second, "Initial refresh interval")
+	flag.BoolVar(&opts.AdaptiveRefresh, "adaptive-refresh", false,
+		"Lengthen the refresh interval while collecting stats takes most of it")
+	flag.StringVar(&opts.ViewRefresh, "view-refresh", "",
+		"Per-view refresh intervals overriding -refresh, e.g. network=1s,processes=5s")
+	flag.BoolVar(&opts.Stream, "stream", false, "Write one JSON object per refresh to stdout instead of running a UI")
+	flag.BoolVar(&opts.Once, "once", false, "Write a single JSON object to stdout and exit")
+	flag.StringVar(&opts.Format, "format", "json",
+		"Output of -once: json, or nagios for a one-shot check line with perfdata and a Nagios exit code")
+	flag.StringVar(&opts.Serve, "serve", "",
+		"Run as an agent for -hosts dashboards, answering GET /stats on this address (e.g. :"+defaultAgentPort+")")
+	flag.StringVar(&opts.Hosts, "hosts", "", "Show a grid of these -serve agents (host[:port], comma-separated) instead of this machine")
+	flag.StringVar(&opts.InfluxUDP, "influx-udp", "", "Send InfluxDB line protocol to this UDP address (host:port) every refresh")
+	flag.StringVar(&opts.TempUnit, "temp-unit", "c", "Temperature unit: c (Celsius) or f (Fahrenheit)")
+	flag.StringVar(&opts.Color, "color", "auto", "Colored output: always, auto (off for NO_COLOR or non-terminal stdout), or never")
+	flag.Float64Var(&opts.WarnThreshold, "warn-threshold", 60, "Usage percentage above which values are shown as medium")
+	flag.Float64Var(&opts.CritThreshold, "crit-threshold", 80, "Usage percentage above which values are shown as high")
+	flag.StringVar(&opts.ExportPath, "export-path", defaultExportPath,
+		"Export destination for the E key: a .json or .csv file, or a directory for timestamped JSON files")
+	flag.DurationVar(&opts.DiskTimeout, "disk-timeout", internal.DefaultDiskTimeout,
+		"How long each filesystem gets to report its usage before it is shown as unresponsive (0 = wait forever)")
+	flag.StringVar(&opts.ExportFormats, "export-formats", "",
+		"Comma-separated export formats (json,csv), one file each from the same snapshot; with -once, export to -export-path and exit")
+	flag.IntVar(&opts.ExportKeep, "export-keep", 0, "Timestamped exports to keep in an -export-path directory (0 = keep all)")
+	flag.StringVar(&opts.ExportDetail, "export-processes", "summary",
+		"Processes in JSON exports: summary (top CPU, memory and I/O lists) or full (every process, no top lists)")
+	flag.StringVar(&opts.Record, "record", "", "Append each refresh's stats to this file as NDJSON for later -replay")
+	flag.StringVar(&opts.Replay, "replay", "", "Show snapshots from a -record file instead of live stats")
+	flag.BoolVar(&opts.ReplayLoop, "replay-loop", false, "Restart -replay from the beginning instead of pausing at the end")
+	flag.StringVar(&opts.NetAlert, "net-alert", "",
+		"Bandwidth alert thresholds in KB/s per interface, e.g. eth0=5000,*=20000 (* = any other interface)")
+	flag.IntVar(&opts.NetAlertSamples, "net-alert-samples", 3, "Consecutive refreshes over a -net-alert threshold before alerting")
+	flag.BoolVar(&opts.ConfirmQuit, "confirm-quit", false, "Ask before Q quits while logging or recording")
+	flag.BoolVar(&opts.Notify, "notify", false, "Show desktop notifications for critical alerts (notify-send on Linux, osascript on macOS)")
+	flag.StringVar(&opts.IfaceFilter, "iface-filter", internal.DefaultInterfaceFilter,
+		"Regular expression of network interfaces to show, or !regexp of interfaces to hide (empty = all)")
+	flag.StringVar(&opts.User, "user", "", "Only show processes of these users (comma-separated)")
+	flag.DurationVar(&opts.GraphWindow, "graph-window", defaultGraphWindow, "Time span of the charts in the graph view (key 7), from 1m to 1h")
+	flag.IntVar(&opts.OverviewDisks, "overview-disks", 3, "Filesystems in the overview, fullest first (0 = all)")
+	flag.IntVar(&opts.TopN, "top-n", internal.DefaultTopProcesses, "Processes in the top CPU, memory and disk I/O lists (half as many in compact mode)")
+	flag.IntVar(&opts.WatchPID, "pid", 0, "Watch a single process in a detail view (key 6)")
+	flag.BoolVar(&opts.ShowSecrets, "show-secrets", false,
+		"Show environment values that look like secrets (names containing SECRET, TOKEN, PASSWORD, API_KEY...) in the W overlay")
+	flag.BoolVar(&opts.Safe, "safe", false,
+		"Read-only mode: disable keys that change the system, such as renicing processes; cannot be turned off while running")
+	flag.BoolVar(&opts.ResolveHosts, "resolve-hosts", false,
+		"Show hostnames of connection remote addresses in the Network view, looked up with reverse DNS in the background (G toggles)")
+	flag.BoolVar(&opts.NoEmoji, "no-emoji", false, "Replace emoji section icons with ASCII labels such as [SYS]")
+	flag.StringVar(&opts.Theme, "theme", DefaultTheme.Name, "Color theme: default, colorblind, or the path of a JSON theme file")
+	flag.StringVar(&opts.BarStyle, "bar-style", BarGradient.String(),
+		"Progress bars: gradient, solid, ascii (for terminals without Unicode blocks) or braille")
+	flag.StringVar(&opts.StatePath, "state-file", defaultStatePath(),
+		"File remembering the view, compact mode, colors and refresh rate between runs (empty = don't remember)")
+	return opts
+}
+
 // Application state
 type App struct {
-	currentView   ViewType
-	refreshRate   time.Duration
-	paused        bool
-	logToFile     bool
-	logFile       *os.File
-	showHelp      bool
-	compactMode   bool
-	colorEnabled  bool
-	exitRequested bool
+	ctx               context.Context // cancelled on quit or SIGTERM; aborts in-flight collection
+	cancel            context.CancelFunc
+	currentView       ViewType
+	refreshRate       time.Duration
+	refreshRates      map[ViewType]time.Duration // per-view overrides of refreshRate
+	adaptiveRefresh   bool                       // back off the refresh rate while collection is slow
+	slowCollections   int                        // consecutive refreshes over adaptiveSlowPercent
+	paused            bool
+	logToFile         bool
+	logFormat         LogFormat
+	logPaused         bool // logging is on but writes nothing until resumed with Shift-P
+	logFile           *RotatingWriter
+	session           *SessionSummary // aggregates of the current or last logging session
+	showHelp          bool
+	showSummary       bool
+	showPeaks         bool
+	showEvents        bool
+	events            []Event               // event log, oldest first
+	notableProcesses  map[processKey]string // last scan's top processes, whose exits are logged
+	peaks             *Peaks
+	commandProc       *internal.ProcessInfo    // process whose full command is shown, nil when closed
+	commandDetails    *internal.ProcessDetails // read when the command overlay opens
+	commandDetailsErr error
+	showSecrets       bool      // show environment values redactEnv would hide
+	safeMode          bool      // -safe; set at startup and never changed, see refuseInSafeMode
+	diffA, diffB      *snapshot // snapshots marked with M; the diff overlay shows once both are set
+	compactMode       bool
+	colorEnabled      bool
+	showProcNet       bool
+	resolveHosts      bool          // show remote hostnames in the Network view's connection list
+	resolver          *hostResolver // reverse DNS cache, kept while resolveHosts is toggled
+	processTree       bool
+	aggregate         bool
+	treeCollapsed     bool
+	showChanges       bool // highlight processes that started or exited since the last scan
+	noEmoji           bool
+	exitRequested     bool
+	cleanedUp         bool // cleanup has run
+	cursorHidden      bool
+	out               io.Writer    // terminal output, os.Stdout outside of tests
+	frame             bytes.Buffer // screen being drawn, written out by flushFrame
+
+	manualRefreshAt    time.Time // when R last refreshed while paused; zero otherwise
+	showAllFilesystems bool
+	diskSort           DiskSortKey
+	overviewDisks      int // filesystems in the overview; 0 shows them all
+	excludedFstypes    []string
+	netSmoothing       float64
+	logInterval        time.Duration
+	logMaxSize         int64
+	logMaxFiles        int
+	logCompress        bool
+	influx             *InfluxSender
+	tempUnit           internal.TempUnit
+	colorMode          string // -color setting, remembered in the state file
+	theme              Theme
+	barStyle           BarStyle
+	warnThreshold      float64
+	critThreshold      float64
+	exportPath         string
+	exportKeep         int
+	exportFormats      []Format // empty to follow the -export-path extension
+	exportDetail       ExportDetail
+	collector          Collector
+	replaying          bool
+	topN               int      // length of the top process lists
+	userFilter         []string // only processes of these users are shown; empty for all
+	cpuOfAllCores      bool     // per-process CPU as a share of all cores rather than of one
+	hosts              []string // -hosts agents; empty without the hosts grid
+	hostSummaries      []HostSummary
+	gpus               []gpu.GPUInfo // latest nvidia-smi result, see queryGPUs
+	gpuErr             error
+	gpusQueried        time.Time
+	queryingGPUs       bool
+	pollingHosts       bool // a pollHosts is waiting for agents
+	hostSelected       int
+	remoteHost         string // agent whose views are open, empty for the grid
+	recordFile         *os.File
+	netSpeeds          []internal.NetworkSpeed // sampled once per refresh
+	diskIO             []internal.DiskIOSpeed  // sampled once per refresh
+	diskHistory        map[string][]diskSample // used bytes by mountpoint, for time-to-full
+	diskSelected       int                     // row of the disks view that Enter inspects
+	mountUsers         *MountUsers             // processes using the inspected mount, nil until Enter
+	bandwidthAlerter   *BandwidthAlerter
+	resourceAlerter    *ResourceAlerter
+	notifier           Notifier
+	ifaceFilter        *internal.InterfaceFilter
+	lastAlert          *Alert
+	netBaseline        *internal.TrafficBaseline // start of the session traffic totals
+	statusMessage      string                    // outcome of the last export or renice, shown in the footer
+	statePath          string
+	prompt             string // label of the line being typed, empty when not prompting
+	promptInput        []rune
+	input              <-chan KeyEvent // key presses, read directly by modal prompts
+	pendingKeys        []KeyEvent      // read during a collection, see collect
+	collecting         bool            // collect is reading the keyboard
+	commands           chan func()     // work from other goroutines, see Do
+	confirmQuit        bool
+	restoreInput       func() // puts the terminal back in line mode
+	cpuHistory         *internal.History
+	memHistory         *internal.History
+	graphSamples       []graphSample // readings within graphWindow, oldest first
+	clock              internal.ClockWatch
+	clockJump          time.Duration // wall-clock step seen at the latest refresh, zero for none
+	logClock           internal.ClockWatch
+	graphWindow        time.Duration
+	watcher            *internal.ProcessWatcher
+	watched            *internal.ProcessInfo
+	shownTarget        *internal.ProcessInfo // top CPU row of the last frame, see targetProcess
+	watchedHistory     *internal.History
+	watchedExited      bool
+	prevProcesses      []internal.ProcessInfo // every process at the previous refresh, for showChanges
+	startedProcs       []internal.ProcessInfo // started since the previous refresh
+	exitedProcs        []internal.ProcessInfo // exited since the previous refresh
+}
+
+// initTUI runs the terminal interface until the user quits or the process
+// receives SIGINT/SIGTERM.
+func initTUI(opts *Options) {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// The last session's settings fill in for flags that were not given
+	var state *UIState
+	if opts.StatePath != "" {
+		var err error
+		if state, err = LoadState(opts.StatePath); err != nil {
+			log.Printf("Ignoring saved UI state: %v", err)
+		} else if state != nil {
+			state.applyTo(opts)
+		}
+	}
+
+	app := &App{
+		ctx:             ctx,
+		commands:        make(chan func()),
+		peaks:           newPeaks(time.Now()),
+		out:             os.Stdout,
+		currentView:     ViewOverview,
+		refreshRate:     opts.RefreshRate,
+		adaptiveRefresh: opts.AdaptiveRefresh,
+		noEmoji:         opts.NoEmoji,
+		showSecrets:     opts.ShowSecrets,
+		safeMode:        opts.Safe,
+		resolveHosts:    opts.ResolveHosts,
+		resolver:        newHostResolver(ctx),
+		showChanges:     true,
+
+		showAllFilesystems: opts.ShowAllFilesystems,
+		excludedFstypes:    splitList(opts.ExcludedFstypes),
+		netSmoothing:       opts.NetSmoothing,
+		logInterval:        opts.LogInterval,
+		logMaxSize:         opts.LogMaxSizeMB * 1024 * 1024,
+		logMaxFiles:        opts.LogMaxFiles,
+		logCompress:        opts.LogCompress,
+		exportPath:         opts.ExportPath,
+		exportKeep:         opts.ExportKeep,
+		overviewDisks:      max(opts.OverviewDisks, 0),
+		statePath:          opts.StatePath,
+		confirmQuit:        opts.ConfirmQuit,
+		collector:          &liveCollector{},
+		resourceAlerter:    NewResourceAlerter(),
+		notifier:           noopNotifier{},
+		cpuHistory:         internal.NewHistory(historySize),
+		memHistory:         internal.NewHistory(historySize),
+	}
+	if app.refreshRate < minRefreshRate {
+		app.refreshRate = minRefreshRate
+	}
+	if opts.ViewRefresh != "" {
+		rates, err := parseViewRefreshRates(opts.ViewRefresh)
+		if err != nil {
+			log.Printf("Ignoring -view-refresh: %v", err)
+		} else {
+			app.refreshRates = rates
+		}
+	}
+	app.graphWindow = min(max(opts.GraphWindow, minGraphWindow), maxGraphWindow)
+	if app.graphWindow != opts.GraphWindow {
+		log.Printf("Clamping -graph-window %s to %s", opts.GraphWindow, app.graphWindow)
+	}
+	if app.logInterval <= 0 {
+		app.logInterval = 3 * time.Second
+	} else if app.logInterval < internal.MinSampleInterval {
+		log.Printf("Raising -log-interval %s to %s, the shortest window CPU and I/O rates are measured over", app.logInterval, internal.MinSampleInterval)
+		app.logInterval = internal.MinSampleInterval
+	}
+
+	app.warnThreshold, app.critThreshold = opts.WarnThreshold, opts.CritThreshold
+	if app.warnThreshold < 0 || app.warnThreshold >= app.critThreshold || app.critThreshold > 100 {
+		log.Printf("Ignoring thresholds %g/%g: need 0 <= warn < crit <= 100", app.warnThreshold, app.critThreshold)
+		app.warnThreshold, app.critThreshold = 60, 80
+	}
+
+	colorEnabled, err := UseColor(opts.Color)
+	if err != nil {
+		log.Printf("Using -color=auto: %v", err)
+		colorEnabled, _ = UseColor("auto")
+		opts.Color = "auto"
+	}
+	app.colorEnabled = colorEnabled
+	app.colorMode = opts.Color
+
+	theme, err := LoadTheme(opts.Theme)
+	if err != nil {
+		log.Printf("Using default theme: %v", err)
+	}
+	app.theme = theme
+
+	if style, err := ParseBarStyle(opts.BarStyle); err == nil {
+		app.barStyle = style
+	} else {
+		log.Printf("Ignoring -bar-style: %v", err)
+	}
+
+	if format, err := ParseLogFormat(opts.LogFormat); err == nil {
+		app.logFormat = format
+	} else {
+		log.Printf("Ignoring -log-format: %v", err)
+	}
+
+	if detail, err := ParseExportDetail(opts.ExportDetail); err == nil {
+		app.exportDetail = detail
+	} else {
+		log.Printf("Ignoring -export-processes: %v", err)
+	}
+
+	if formats, err := ParseExportFormats(opts.ExportFormats); err == nil {
+		app.exportFormats = formats
+	} else {
+		log.Printf("Ignoring -export-formats: %v", err)
+	}
+
+	if unit, err := internal.ParseTempUnit(opts.TempUnit); err == nil {
+		app.tempUnit = unit
+	} else {
+		log.Printf("Ignoring -temp-unit: %v", err)
+	}
+
+	if state != nil {
+		app.compactMode = state.CompactMode
+		// The process view needs -pid, which is not remembered
+		if state.View >= ViewOverview && state.View <= ViewGraph && state.View != ViewProcess {
+			app.currentView = state.View
+		}
+	}
+
+	if opts.Replay != "" {
+		replay, err := newReplayCollector(opts.Replay, opts.ReplayLoop)
+		if err != nil {
+			log.Printf("Cannot replay, showing live stats: %v", err)
+		} else {
+			app.collector = replay
+			app.replaying = true
+		}
+	}
+
+	if opts.Record != "" {
+		file, err := os.OpenFile(opts.Record, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			log.Printf("Cannot record: %v", err)
+		} else {
+			app.recordFile = file
+		}
+	}
+
+	app.setTopN(opts.TopN)
+	app.userFilter = parseUserFilter(opts.User)
+
+	if opts.Hosts != "" {
+		if app.replaying {
+			log.Printf("Ignoring -hosts while replaying")
+		} else {
+			app.hosts = splitList(opts.Hosts)
+		}
+	}
+
+	// Session traffic totals count from startup until reset with B
+	if netStats, err := app.collector.NetworkStats(ctx); err == nil {
+		app.netBaseline = internal.NewTrafficBaseline(netStats)
+	}
+
+	if opts.Notify {
+		notifier, err := NewDesktopNotifier(notifyInterval)
+		if err != nil {
+			log.Printf("Desktop notifications disabled: %v", err)
+		} else {
+			app.notifier = notifier
+		}
+	}
+
+	if filter, err := internal.ParseInterfaceFilter(opts.IfaceFilter); err != nil {
+		log.Printf("Ignoring -iface-filter: %v", err)
+	} else {
+		app.ifaceFilter = filter
+		internal.SetInterfaceFilter(filter)
+	}
+
+	if opts.NetAlert != "" {
+		thresholds, err := ParseBandwidthThresholds(opts.NetAlert)
+		if err != nil {
+			log.Printf("Ignoring -net-alert: %v", err)
+		} else {
+			app.bandwidthAlerter = NewBandwidthAlerter(thresholds, opts.NetAlertSamples)
+		}
+	}
+
+	if opts.WatchPID > 0 {
+		watcher, err := internal.NewProcessWatcher(ctx, int32(opts.WatchPID))
+		if err != nil {
+			log.Printf("Cannot watch PID %d: %v", opts.WatchPID, err)
+		} else {
+			app.watcher = watcher
+			app.watchedHistory = internal.NewHistory(historySize)
+			app.currentView = ViewProcess
+		}
+	}
+
+	if opts.InfluxUDP != "" {
+		sender, err := NewInfluxSender(opts.InfluxUDP)
+		if err != nil {
+			log.Printf("Error opening InfluxDB UDP output: %v", err)
+		} else {
+			app.influx = sender
+			defer sender.Close()
+		}
+	}
+
+	// A panic in a display function must not leave the terminal in raw
+	// mode with colors on; restore it, then let the panic print its trace
+	defer func() {
+		if r := recover(); r != nil {
+			app.cleanup()
+			panic(r)
+		}
+	}()
+
+	restoreInput, err := enableRawInput(os.Stdin)
+	if err != nil {
+		log.Printf("Keys need Enter: %v", err)
+	} else {
+		app.restoreInput = restoreInput
+	}
+	// The cursor would otherwise jump around the screen on every redraw
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Fprint(app.out, "\033[?25l")
+		app.cursorHidden = true
+	}
+
+	inputChan := make(chan KeyEvent)
+	go handleKeyboardInput(os.Stdin, inputChan)
+	app.input, app.cancel = inputChan, cancel
+
+	// The first process scan is the slowest; q can cut it short too
+	app.collect(func() {
+		app.pollHosts()
+		app.recordUsage()
+		app.sampleNetSpeeds()
+		app.sampleDiskIO()
+		app.sampleDiskUsage()
+		app.sampleWatched()
+		app.checkResources()
+		app.displayInterface()
+	})
+
+	app.run()
+}
+
+// run is the main loop. It refreshes, logs, handles key presses and runs
+// the work other goroutines hand over with Do, until app.ctx is cancelled
+// or a key quits; app.cancel is called on quitting by key.
+func (app *App) run() {
+	ctx, inputChan := app.ctx, app.input
+	ticker := time.NewTicker(app.currentRefreshRate())
+	defer ticker.Stop()
+
+	// Logging runs on its own cadence regardless of view or pause state
+	logTicker := time.NewTicker(app.logInterval)
+	defer logTicker.Stop()
+
+	for {
+		// Keys pressed while a collection ran, in the order typed
+		if len(app.pendingKeys) > 0 {
+			key := app.pendingKeys[0]
+			app.pendingKeys = app.pendingKeys[1:]
+			if app.handleKeyPress(key) {
+				app.cancel()
+				app.shutdown()
+				return
+			}
+			ticker.Reset(app.currentRefreshRate())
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			app.shutdown()
+			return
+		case <-ticker.C:
+			if !app.paused {
+				app.refresh()
+				ticker.Reset(app.currentRefreshRate()) // -adaptive-refresh may have changed it
+			} else if app.influx != nil {
+				app.sendInflux() // a refresh sends its own sample
+			}
+		case <-logTicker.C:
+			if app.logToFile && !app.logPaused {
+				app.collectAndLog()
+			}
+		case key, ok := <-inputChan:
+			if !ok {
+				// stdin hit EOF; a nil channel never receives, so keep
+				// monitoring until a signal arrives instead of spinning
+				inputChan = nil
+				continue
+			}
+			if app.handleKeyPress(key) {
+				app.cancel()
+				app.shutdown()
+				return
+			}
+			// Also picks up the new view's rate after a view switch
+			ticker.Reset(app.currentRefreshRate())
+		case fn := <-app.commands:
+			fn()
+			ticker.Reset(app.currentRefreshRate())
+		}
+	}
 }
 
+func (app *App) handleKeyPress(event KeyEvent) bool {
+	switch event.Key {
+	case KeyTab, KeyRight:
+		app.cycleView(1)
+		return false
+	case KeyShiftTab, KeyLeft:
+		app.cycleView(-1)
+		return false
+	case KeyEscape:
+		if app.showHelp || app.showSummary || app.showPeaks || app.showEvents || app.commandProc != nil || app.diffB != nil {
+			app.showHelp, app.showSummary, app.showPeaks, app.showEvents, app.commandProc = false, false, false, false, nil
+			app.diffA, app.diffB = nil, nil
+			app.displayInterface()
+		} else if app.remoteHost != "" {
+			app.closeHost()
+		}
+		return false
+	case KeyUp, KeyDown:
+		step := 1
+		if event.Key == KeyUp {
+			step = -1
+		}
+		if app.showingHosts() && len(app.hostSummaries) > 0 {
+			app.hostSelected = (app.hostSelected + step + len(app.hostSummaries)) % len(app.hostSummaries)
+			app.displayInterface()
+		} else if app.currentView == ViewDisks && app.localStats() {
+			app.selectDisk(step)
+		}
+		return false
+	case KeyEnter:
+		if app.showingHosts() && app.hostSelected < len(app.hostSummaries) {
+			app.openHost(app.hostSummaries[app.hostSelected].Host)
+		} else if app.currentView == ViewDisks && app.localStats() {
+			app.findMountUsers()
+		}
+		return false
+	case KeyRune:
+	default:
+		return false
+	}
 
-func (app *App) handleKeyPress(key rune) bool {
-	switch key {
+	switch event.Rune {
 	case 'q', 'Q':
+		if app.confirmQuit {
+			if capture := app.activeCapture(); capture != "" {
+				return app.promptYesNo(capture + " is active, quit anyway?")
+			}
+		}
 		return true // Exit
 	case 'h', 'H', '?':
 		app.showHelp = !app.showHelp
@@ -75,44 +744,341 @@ func (app *App) handleKeyPress(key rune) bool {
 	case '5':
 		app.currentView = ViewSystem
 		app.displayInterface()
-	case 'p', 'P':
+	case '6':
+		if app.watcher != nil {
+			app.currentView = ViewProcess
+			app.displayInterface()
+		}
+	case '7':
+		app.currentView = ViewGraph
+		app.displayInterface()
+	case 'P':
+		app.toggleLogPause()
+	case 'p':
 		app.paused = !app.paused
+		app.manualRefreshAt = time.Time{}
+		if app.paused {
+			app.addEvent("Paused")
+		} else {
+			app.addEvent("Resumed")
+		}
 		app.displayInterface()
 	case 'c', 'C':
 		app.compactMode = !app.compactMode
 		app.displayInterface()
 	case 'l', 'L':
 		app.toggleLogging()
+	case 's', 'S':
+		app.showSummary = !app.showSummary
+		app.displayInterface()
+	case 'w', 'W':
+		app.toggleCommand()
+	case 'k', 'K':
+		app.showPeaks = !app.showPeaks
+		app.displayInterface()
+	case 'z', 'Z':
+		app.peaks = newPeaks(time.Now())
+		app.statusMessage = "Peaks reset"
+		app.addEvent("Peaks reset")
+		app.displayInterface()
+	case 'j', 'J':
+		app.showEvents = !app.showEvents
+		app.displayInterface()
+	case 'm', 'M':
+		app.markSnapshot()
 	case 'e', 'E':
 		app.exportStats()
+	case 'f', 'F':
+		app.showAllFilesystems = !app.showAllFilesystems
+		app.displayInterface()
+	case 'd', 'D':
+		app.showChanges = !app.showChanges
+		app.prevProcesses, app.startedProcs, app.exitedProcs = nil, nil, nil
+		app.displayInterface()
+	case 'o', 'O':
+		app.diskSort = (app.diskSort + 1) % diskSortKeys
+		app.displayInterface()
+	case 't', 'T':
+		if app.tempUnit == internal.Celsius {
+			app.tempUnit = internal.Fahrenheit
+		} else {
+			app.tempUnit = internal.Celsius
+		}
+		app.displayInterface()
+	case 'n', 'N':
+		app.showProcNet = !app.showProcNet
+		app.displayInterface()
+	case 'g', 'G':
+		app.resolveHosts = !app.resolveHosts
+		app.displayInterface()
+	case 'a', 'A':
+		app.aggregate = !app.aggregate
+		app.displayInterface()
+	case 'v', 'V':
+		app.processTree = !app.processTree
+		app.displayInterface()
+	case 'x', 'X':
+		app.treeCollapsed = !app.treeCollapsed
+		app.displayInterface()
+	case 'b':
+		app.jumpToUrgentView()
+	case 'B':
+		app.netBaseline = nil // re-captured on the next network stats
+		app.displayInterface()
+	case 'i', 'I':
+		app.noEmoji = !app.noEmoji
+		app.displayInterface()
 	case 'r', 'R':
-		app.displayInterface() // Refresh
-	case '+':
-		if app.refreshRate > time.Second {
-			app.refreshRate -= time.Second
-			ticker := time.NewTicker(app.refreshRate)
-			defer ticker.Stop()
+		// A manual refresh takes a full sample even while paused, and is
+		// logged immediately so the log reflects what was on screen
+		if app.paused {
+			app.manualRefreshAt = time.Now()
+		}
+		app.refresh()
+		if app.logToFile && !app.logPaused {
+			app.collectAndLog()
+		}
+	case '/':
+		// Typed as one line, e.g. "/!^veth" then Enter; "/" alone shows all
+		if spec, ok := app.promptLine(fmt.Sprintf("Interface filter [%s]", app.ifaceFilter)); ok {
+			app.setInterfaceFilter(spec)
+		}
+	case 'u', 'U':
+		// Comma-separated user names; an empty line shows everyone's processes
+		if spec, ok := app.promptLine(fmt.Sprintf("User filter [%s]", strings.Join(app.userFilter, ","))); ok {
+			app.userFilter = parseUserFilter(spec)
+			app.statusMessage = "Showing processes of all users"
+			if len(app.userFilter) > 0 {
+				app.statusMessage = "Showing processes of " + strings.Join(app.userFilter, ", ")
+			}
+			app.displayInterface()
 		}
+	case '>':
+		app.renice(+5)
+	case '<':
+		app.renice(-5)
+	case '%':
+		app.cpuOfAllCores = !app.cpuOfAllCores
+		app.displayInterface()
+	case ']':
+		app.setTopN(app.topN + 5)
+		app.displayInterface()
+	case '[':
+		app.setTopN(app.topN - 5)
+		app.displayInterface()
+	case '+':
+		app.adjustRefreshRate(-time.Second)
 	case '-':
-		if app.refreshRate < 10*time.Second {
-			app.refreshRate += time.Second
-			ticker := time.NewTicker(app.refreshRate)
-			defer ticker.Stop()
+		app.adjustRefreshRate(time.Second)
+	}
+	return false
+}
+
+// setInterfaceFilter applies a filter typed at the / prompt, reporting the
+// outcome in the footer
+func (app *App) setInterfaceFilter(spec string) {
+	filter, err := internal.ParseInterfaceFilter(strings.TrimSpace(spec))
+	if err != nil {
+		app.statusMessage = err.Error()
+	} else {
+		app.ifaceFilter = filter
+		internal.SetInterfaceFilter(filter)
+		app.statusMessage = "Interface filter: " + filter.String()
+		if filter == nil {
+			app.statusMessage = "Showing all interfaces"
+		}
+	}
+	app.displayInterface()
+}
+
+// parseUserFilter splits a comma-separated list of user names
+func parseUserFilter(spec string) []string {
+	var users []string
+	for _, user := range strings.Split(spec, ",") {
+		if user = strings.TrimSpace(user); user != "" {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+// matchesUser reports whether username is one of users. Windows names
+// (DOMAIN\user) also match on the part after the domain.
+func matchesUser(users []string, username string) bool {
+	short := username[strings.LastIndex(username, `\`)+1:]
+	for _, user := range users {
+		if strings.EqualFold(user, username) || strings.EqualFold(user, short) {
+			return true
 		}
 	}
 	return false
 }
 
+// processStats returns the process stats the views show: those of the
+// -user filter's users, with top lists of their processes only
+func (app *App) processStats() (*internal.ProcessStats, error) {
+	stats, err := app.collector.ProcessStats(app.ctx)
+	if err != nil || len(app.userFilter) == 0 {
+		return stats, err
+	}
+	return internal.FilterProcesses(stats, func(proc internal.ProcessInfo) bool {
+		return matchesUser(app.userFilter, proc.Username)
+	}), nil
+}
+
+// activeCapture names the logging or recording that quitting would end,
+// or returns "" when there is none
+func (app *App) activeCapture() string {
+	switch {
+	case app.logToFile:
+		return "Logging"
+	case app.recordFile != nil:
+		return "Recording"
+	}
+	return ""
+}
+
+// setTopN changes the length of the top process lists, at least 1. Longer
+// lists fill in on the next refresh.
+func (app *App) setTopN(n int) {
+	app.topN = max(n, 1)
+	internal.SetTopProcesses(app.topN)
+}
+
+// topLimit is how many processes the top lists show; compact mode halves it
+func (app *App) topLimit() int {
+	if app.compactMode {
+		return max(app.topN/2, 1)
+	}
+	return app.topN
+}
+
+// views returns the views that can be shown, in tab order; the watched
+// process view is only available with -pid
+func (app *App) views() []ViewType {
+	var views []ViewType
+	for view := ViewOverview; view <= ViewGraph; view++ {
+		if view != ViewProcess || app.watcher != nil {
+			views = append(views, view)
+		}
+	}
+	return views
+}
+
+// cycleView moves to the next (step 1) or previous (step -1) view, wrapping
+// around
+func (app *App) cycleView(step int) {
+	views := app.views()
+	i := slices.Index(views, app.currentView)
+	app.currentView = views[(i+step+len(views))%len(views)]
+	app.displayInterface()
+}
+
+// currentRefreshRate is the refresh interval of the active view
+func (app *App) currentRefreshRate() time.Duration {
+	if rate, ok := app.refreshRates[app.currentView]; ok {
+		return rate
+	}
+	return app.refreshRate
+}
+
+// adjustRefreshRate changes the active view's interval by delta, within
+// the allowed bounds. Views without their own interval share the global one.
+func (app *App) adjustRefreshRate(delta time.Duration) {
+	rate := app.currentRefreshRate() + delta
+	if (delta < 0 && rate < minRefreshRate) || (delta > 0 && rate > maxRefreshRate) {
+		return
+	}
+	if _, ok := app.refreshRates[app.currentView]; ok {
+		app.refreshRates[app.currentView] = rate
+	} else {
+		app.refreshRate = rate
+	}
+}
+
+// adaptRefreshRate lengthens the active view's interval by a second once
+// collection has been slow for adaptiveSlowRefreshes refreshes in a row
+func (app *App) adaptRefreshRate() {
+	timing, ok := app.collectTiming()
+	if !app.adaptiveRefresh || !ok {
+		return
+	}
+	rate := app.currentRefreshRate()
+	if timing.Total() < rate*adaptiveSlowPercent/100 {
+		app.slowCollections = 0
+		return
+	}
+	app.slowCollections++
+	if app.slowCollections < adaptiveSlowRefreshes || rate >= maxRefreshRate {
+		return
+	}
+	app.slowCollections = 0
+	app.adjustRefreshRate(time.Second)
+	app.addEvent("Refresh slowed to %s: collecting took %s", app.currentRefreshRate(), timing.Total().Round(time.Millisecond))
+}
+
+// parseViewRefreshRates parses "network=1s,processes=5s" style specs
+func parseViewRefreshRates(spec string) (map[ViewType]time.Duration, error) {
+	rates := make(map[ViewType]time.Duration)
+	for _, entry := range splitList(spec) {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid view refresh %q, want view=duration", entry)
+		}
+		view, ok := viewFlagNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown view %q", name)
+		}
+		rate, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid view refresh %q: %w", entry, err)
+		}
+		if rate < minRefreshRate {
+			rate = minRefreshRate
+		}
+		rates[view] = rate
+	}
+	return rates, nil
+}
+
 func (app *App) displayInterface() {
-	app.clearScreen()
+	app.frame.Reset()
+	defer app.flushFrame()
 
 	if app.showHelp {
 		app.displayHelp()
 		return
 	}
+	if app.showSummary {
+		app.displaySessionSummary()
+		return
+	}
+	if app.commandProc != nil {
+		app.displayCommand()
+		return
+	}
+	if app.showPeaks {
+		app.displayPeaks()
+		return
+	}
+	if app.showEvents {
+		app.displayEvents()
+		return
+	}
+	if app.diffB != nil {
+		app.displaySnapshotDiff()
+		return
+	}
 
+	app.shownTarget = nil // set again if this frame lists processes
 	app.displayHeader()
 
+	if app.showingHosts() {
+		app.displayHostsView()
+		app.displayFooter()
+		return
+	}
+
 	switch app.currentView {
 	case ViewOverview:
 		app.displayOverviewView()
@@ -124,80 +1090,103 @@ func (app *App) displayInterface() {
 		app.displayDisksView()
 	case ViewSystem:
 		app.displaySystemView()
+	case ViewProcess:
+		app.displayProcessView()
+	case ViewGraph:
+		app.displayGraphView()
 	}
 
 	app.displayFooter()
 }
 
 func (app *App) displayHeader() {
-	viewNames := []string{"Overview", "Processes", "Network", "Disks", "System"}
 	statusColor := ColorGreen
 	if app.paused {
 		statusColor = ColorYellow
 	}
 
 	// Top border
-	fmt.Print(app.colorize("┌", ColorCyan))
-	fmt.Print(app.colorize(strings.Repeat("─", 78), ColorCyan))
-	fmt.Print(app.colorize("┐", ColorCyan))
-	fmt.Println()
+	fmt.Fprint(&app.frame, app.colorize("┌", app.theme.Border))
+	fmt.Fprint(&app.frame, app.colorize(strings.Repeat("─", 78), app.theme.Border))
+	fmt.Fprint(&app.frame, app.colorize("┐", app.theme.Border))
+	fmt.Fprintln(&app.frame)
 
 	// Title and status
-	title := fmt.Sprintf("System Monitor v1.0 - %s View", viewNames[app.currentView])
+	viewName := viewNames[app.currentView]
+	if app.showingHosts() {
+		viewName = "Hosts"
+	}
+	title := fmt.Sprintf("System Monitor v%s - %s View", Version, viewName)
 	status := "RUNNING"
 	if app.paused {
 		status = "PAUSED"
 	}
 
-	fmt.Printf("│ %s%s%s%s │\n",
-		app.colorize(title, ColorBold+ColorWhite),
+	fmt.Fprintf(&app.frame, "│ %s%s%s%s │\n",
+		app.colorize(title, app.theme.Header),
 		strings.Repeat(" ", 78-len(title)-len(status)-3),
 		app.colorize(status, ColorBold+statusColor),
 		app.colorize("", ColorReset))
 
 	// Time and refresh info
 	timeStr := time.Now().Format("15:04:05")
-	refreshStr := fmt.Sprintf("Refresh: %v", app.refreshRate)
-	fmt.Printf("│ %s%s%s │\n",
+	if replay, ok := app.source().(*replayCollector); ok {
+		timeStr = "Replay of " + replay.current().Timestamp
+	} else if app.paused && !app.manualRefreshAt.IsZero() {
+		timeStr = app.manualRefreshAt.Format("15:04:05") + " (manual)"
+	}
+	refreshStr := fmt.Sprintf("Refresh: %v", app.currentRefreshRate())
+	if len(app.userFilter) > 0 {
+		refreshStr = app.truncateString("User: "+strings.Join(app.userFilter, ","), 24) + " | " + refreshStr
+	}
+	if app.remoteHost != "" {
+		// The host is shortened first when the line runs out of room,
+		// keeping a space before refreshStr
+		room := 78 - utf8.RuneCountInString(timeStr) - utf8.RuneCountInString(refreshStr) - len(" on ") - 1
+		if room >= 4 {
+			timeStr += " on " + app.truncateString(app.remoteHost, min(room, 40))
+		}
+	}
+	fmt.Fprintf(&app.frame, "│ %s%s%s │\n",
 		app.colorize(timeStr, ColorCyan),
-		strings.Repeat(" ", 78-len(timeStr)-len(refreshStr)),
+		strings.Repeat(" ", max(0, 78-utf8.RuneCountInString(timeStr)-utf8.RuneCountInString(refreshStr))),
 		app.colorize(refreshStr, ColorDim))
 
 	// Navigation tabs
-	fmt.Print(app.colorize("├", ColorCyan))
-	fmt.Print(app.colorize(strings.Repeat("─", 78), ColorCyan))
-	fmt.Print(app.colorize("┤", ColorCyan))
-	fmt.Println()
+	fmt.Fprint(&app.frame, app.colorize("├", app.theme.Border))
+	fmt.Fprint(&app.frame, app.colorize(strings.Repeat("─", 78), app.theme.Border))
+	fmt.Fprint(&app.frame, app.colorize("┤", app.theme.Border))
+	fmt.Fprintln(&app.frame)
 
 	tabStr := ""
-	for i, name := range viewNames {
-		prefix := fmt.Sprintf("[%d]", i+1)
-		if ViewType(i) == app.currentView {
+	for _, view := range app.views() {
+		prefix, name := fmt.Sprintf("[%d]", view+1), viewNames[view]
+		if view == app.currentView {
 			tabStr += app.colorize(fmt.Sprintf("%s%s ", prefix, name), ColorBold+ColorYellow)
 		} else {
 			tabStr += app.colorize(fmt.Sprintf("%s%s ", prefix, name), ColorDim)
 		}
 	}
 
-	fmt.Printf("│ %s%s │\n", tabStr, strings.Repeat(" ", 78-len(stripColors(tabStr))))
+	fmt.Fprintf(&app.frame, "│ %s%s │\n", tabStr, strings.Repeat(" ", 78-len(stripColors(tabStr))))
 
 	// Bottom border of header
-	fmt.Print(app.colorize("└", ColorCyan))
-	fmt.Print(app.colorize(strings.Repeat("─", 78), ColorCyan))
-	fmt.Print(app.colorize("┘", ColorCyan))
-	fmt.Println()
-	fmt.Println()
+	fmt.Fprint(&app.frame, app.colorize("└", app.theme.Border))
+	fmt.Fprint(&app.frame, app.colorize(strings.Repeat("─", 78), app.theme.Border))
+	fmt.Fprint(&app.frame, app.colorize("┘", app.theme.Border))
+	fmt.Fprintln(&app.frame)
+	fmt.Fprintln(&app.frame)
 }
 
 func (app *App) displayOverviewView() {
-	stats, err := internal.GetSystemStats()
+	stats, err := app.collector.SystemStats(app.ctx)
 	if err != nil {
-		fmt.Printf(app.colorize("Error getting system stats: %v\n", ColorRed), err)
+		fmt.Fprintf(&app.frame, app.colorize("Error getting system stats: %v\n", ColorRed), err)
 		return
 	}
 
-	procStats, _ := internal.GetProcessStats()
-	netStats, _ := internal.GetNetworkStats()
+	procStats, _ := app.processStats()
+	netStats, _ := app.collector.NetworkStats(app.ctx)
 
 	app.displaySystemOverview(stats)
 
@@ -208,208 +1197,600 @@ func (app *App) displayOverviewView() {
 	if netStats != nil {
 		app.displayNetworkSummary(netStats)
 	}
-
-	// Log stats if enabled
-	if app.logToFile {
-		app.logStats(stats, procStats, netStats)
-	}
 }
 
 func (app *App) displaySystemOverview(stats *internal.SystemStats) {
 	// System Info
-	fmt.Printf("%s🖥️  System Information%s\n", app.colorize("", ColorBold+ColorBlue), app.colorize("", ColorReset))
-	fmt.Printf("   Hostname: %s | OS: %s | Uptime: %s\n\n",
-		app.colorize(stats.Host.Hostname, ColorCyan),
-		app.colorize(stats.Host.OS, ColorCyan),
-		app.colorize(internal.FormatUptime(stats.Host.Uptime), ColorGreen))
+	if app.sectionAvailable(stats, "host") {
+		fmt.Fprintf(&app.frame, "%s%s System Information%s\n", app.colorize("", ColorBold+ColorBlue), app.icon("🖥️"), app.colorize("", ColorReset))
+		fmt.Fprintf(&app.frame, "   Hostname: %s | OS: %s | Uptime: %s\n\n",
+			app.colorize(stats.Host.Hostname, ColorCyan),
+			app.colorize(stats.Host.OS, ColorCyan),
+			app.colorize(internal.FormatUptime(stats.Host.Uptime), ColorGreen))
+	}
 
 	// CPU
-	cpuColor := app.getUsageColor(stats.CPU.Usage)
-	fmt.Printf("%s🔧 CPU Usage: %.1f%%%s %s\n",
-		app.colorize("", ColorBold+ColorBlue),
-		stats.CPU.Usage,
-		app.colorize("", ColorReset),
-		app.getProgressBar(stats.CPU.Usage, 40, cpuColor))
+	if app.sectionAvailable(stats, "cpu") {
+		// Inside a cgroup with a CPU quota, usage of the quota is what counts
+		label, usage := "CPU Usage", stats.CPU.Usage
+		if stats.CPU.CgroupCPUQuota > 0 {
+			label = fmt.Sprintf("CPU Usage (of %.1f CPU quota)", stats.CPU.CgroupCPUQuota)
+			usage = stats.CPU.CgroupUsage
+		}
+		cpuColor := app.getUsageColor(usage)
+		fmt.Fprintf(&app.frame, "%s%s %s: %.1f%%%s %s %s\n",
+			app.colorize("", ColorBold+ColorBlue),
+			app.icon("🔧"),
+			label,
+			usage,
+			app.colorize("", ColorReset),
+			app.colorize(internal.Sparkline(app.cpuHistory.Values(), sparklineWidth), cpuColor),
+			app.getProgressBar(usage, 40, cpuColor))
 
-	if !app.compactMode {
-		fmt.Printf("   Cores: %d | Model: %s\n\n",
-			stats.CPU.Cores,
-			app.colorize(app.truncateString(stats.CPU.ModelName, 50), ColorDim))
+		if !app.compactMode {
+			fmt.Fprintf(&app.frame, "   Cores: %d | Model: %s\n\n",
+				stats.CPU.Cores,
+				app.colorize(app.truncateString(stats.CPU.ModelName, 50), ColorDim))
+		}
 	}
 
 	// Memory
-	memColor := app.getUsageColor(stats.Memory.UsedPercent)
-	fmt.Printf("%s💾 Memory: %.1f%%%s %s\n",
-		app.colorize("", ColorBold+ColorBlue),
-		stats.Memory.UsedPercent,
-		app.colorize("", ColorReset),
-		app.getProgressBar(stats.Memory.UsedPercent, 40, memColor))
+	if app.sectionAvailable(stats, "memory") {
+		// Likewise a cgroup memory limit is what the OOM killer enforces
+		label, usedPercent := "Memory", stats.Memory.UsedPercent
+		used, total, available := stats.Memory.Used, stats.Memory.Total, stats.Memory.Available
+		if stats.Memory.CgroupMemLimit > 0 {
+			label, usedPercent = "Memory (of cgroup limit)", stats.Memory.CgroupUsedPercent
+			used, total = stats.Memory.CgroupMemUsed, stats.Memory.CgroupMemLimit
+			available = 0
+			if used < total {
+				available = total - used
+			}
+		}
+		memColor := app.getUsageColor(usedPercent)
+		fmt.Fprintf(&app.frame, "%s%s %s: %.1f%%%s %s %s\n",
+			app.colorize("", ColorBold+ColorBlue),
+			app.icon("💾"),
+			label,
+			usedPercent,
+			app.colorize("", ColorReset),
+			app.colorize(internal.Sparkline(app.memHistory.Values(), sparklineWidth), memColor),
+			app.getProgressBar(usedPercent, 40, memColor))
+
+		// Pressure counts cache as available, so it is a better gauge of OOM
+		// risk than UsedPercent
+		pressure := fmt.Sprintf("   Pressure: %s",
+			app.colorize(fmt.Sprintf("%.0f%%", stats.Memory.Pressure), app.getUsageColor(stats.Memory.Pressure)))
+		if stats.Memory.PSI >= 0 {
+			pressure += fmt.Sprintf(" | Stalled (PSI avg10): %s",
+				app.colorize(fmt.Sprintf("%.1f%%", stats.Memory.PSI), app.getUsageColor(stats.Memory.PSI)))
+		}
+		fmt.Fprintln(&app.frame, pressure)
 
-	if !app.compactMode {
-		fmt.Printf("   Used: %s / %s | Free: %s\n\n",
-			app.colorize(internal.FormatBytes(stats.Memory.Used), ColorYellow),
-			app.colorize(internal.FormatBytes(stats.Memory.Total), ColorCyan),
-			app.colorize(internal.FormatBytes(stats.Memory.Available), ColorGreen))
+		if !app.compactMode {
+			fmt.Fprintf(&app.frame, "   Used: %s / %s | Available: %s\n\n",
+				app.colorize(internal.FormatBytes(used), ColorYellow),
+				app.colorize(internal.FormatBytes(total), ColorCyan),
+				app.colorize(internal.FormatBytes(available), ColorGreen))
+		}
 	}
 
 	// Disk Usage Summary
-	if !app.compactMode {
-		fmt.Printf("%s💽 Disk Usage:%s\n", app.colorize("", ColorBold+ColorBlue), app.colorize("", ColorReset))
-		for i, disk := range stats.Disk {
-			if i >= 3 { // Show max 3 disks in overview
-				break
-			}
+	if !app.compactMode && app.sectionAvailable(stats, "disk") {
+		fmt.Fprintf(&app.frame, "%s%s Disk Usage:%s\n", app.colorize("", ColorBold+ColorBlue), app.icon("💽"), app.colorize("", ColorReset))
+		disks, hidden := app.overviewDiskList(stats.Disk)
+		for _, disk := range disks {
 			diskColor := app.getUsageColor(disk.UsedPercent)
 			device := app.truncateString(filepath.Base(disk.Device), 15)
-			fmt.Printf("   %-15s %6.1f%% %s %s / %s\n",
+			if disk.Unresponsive {
+				fmt.Fprintf(&app.frame, "   %-15s %s\n", app.colorize(device, ColorCyan), app.colorize("not responding: "+disk.Mountpoint, ColorBold+ColorRed))
+				continue
+			}
+			fmt.Fprintf(&app.frame, "   %-15s %6.1f%% %s %s / %s\n",
 				app.colorize(device, ColorCyan),
 				disk.UsedPercent,
 				app.getProgressBar(disk.UsedPercent, 20, diskColor),
 				app.colorize(internal.FormatBytes(disk.Used), ColorYellow),
 				app.colorize(internal.FormatBytes(disk.Total), ColorDim))
 		}
-		fmt.Println()
+		if hidden > 0 {
+			fmt.Fprintln(&app.frame, app.colorize(fmt.Sprintf("   ... and %d more in the Disks view (4)", hidden), ColorDim))
+		}
+		fmt.Fprintln(&app.frame)
+	}
+}
+
+// overviewDiskList picks the filesystems shown in the overview: any that
+// stopped responding, then the fullest, whatever the Disks view's sort, so
+// a nearly full volume is never the one cut off. It returns them and how
+// many were left out.
+func (app *App) overviewDiskList(all []internal.DiskInfo) ([]internal.DiskInfo, int) {
+	disks := sortDisks(app.visibleDisks(all), DiskSortUsed)
+	sort.SliceStable(disks, func(i, j int) bool {
+		return disks[i].Unresponsive && !disks[j].Unresponsive
+	})
+
+	if app.overviewDisks == 0 || len(disks) <= app.overviewDisks {
+		return disks, 0
 	}
+	return disks[:app.overviewDisks], len(disks) - app.overviewDisks
+}
+
+// formatCPUFrequency describes the current and maximum CPU frequency,
+// leaving out whichever is unknown
+func formatCPUFrequency(c internal.CPUInfo) string {
+	switch {
+	case c.FreqCurrentMHz > 0 && c.FreqMaxMHz > 0:
+		return fmt.Sprintf("%.0f / %.0f MHz (%.0f%% of max)", c.FreqCurrentMHz, c.FreqMaxMHz, 100*c.FreqCurrentMHz/c.FreqMaxMHz)
+	case c.FreqCurrentMHz > 0:
+		return fmt.Sprintf("%.0f MHz", c.FreqCurrentMHz)
+	case c.FreqMaxMHz > 0:
+		return fmt.Sprintf("max %.0f MHz", c.FreqMaxMHz)
+	}
+	return ""
+}
+
+// sectionAvailable reports whether a section of stats was collected,
+// printing a note in its place when it was not
+func (app *App) sectionAvailable(stats *internal.SystemStats, section string) bool {
+	reason, failed := stats.Errors[section]
+	if failed {
+		fmt.Fprintf(&app.frame, "%s\n\n", app.colorize(fmt.Sprintf("%s %s stats unavailable: %s", app.icon("⚠"), section, reason), ColorRed))
+	}
+	return !failed
 }
 
 func (app *App) displayProcessSummary(stats *internal.ProcessStats) {
-	fmt.Printf("%s📄 Process Summary%s\n", app.colorize("", ColorBold+ColorPurple), app.colorize("", ColorReset))
-	fmt.Printf("   Total: %s | Running: %s | Sleeping: %s\n\n",
+	fmt.Fprintf(&app.frame, "%s%s Process Summary%s%s\n", app.colorize("", ColorBold+ColorPurple), app.icon("📄"), app.colorize("", ColorReset), app.staleNote(stats))
+	fmt.Fprintf(&app.frame, "   Total: %s | Running: %s | Sleeping: %s\n",
 		app.colorize(fmt.Sprintf("%d", stats.TotalProcesses), ColorCyan),
 		app.colorize(fmt.Sprintf("%d", stats.RunningProcs), ColorGreen),
 		app.colorize(fmt.Sprintf("%d", stats.SleepingProcs), ColorYellow))
+	// Zombies pile up when a parent does not reap its children
+	if stats.ZombieProcs > 0 {
+		fmt.Fprintf(&app.frame, "   %s\n", app.colorize(fmt.Sprintf("%s %d zombie processes, check their parents (Processes view)",
+			app.icon("⚠"), stats.ZombieProcs), ColorBold+ColorRed))
+	}
+	fmt.Fprintln(&app.frame)
 
 	if !app.compactMode {
-		fmt.Printf("%s🔥 Top CPU Processes:%s\n", app.colorize("", ColorBold+ColorRed), app.colorize("", ColorReset))
+		fmt.Fprintf(&app.frame, "%s%s Top CPU Processes:%s\n", app.colorize("", ColorBold+ColorRed), app.icon("🔥"), app.colorize("", ColorReset))
 		for i, proc := range stats.TopCPU {
 			if i >= 3 || proc.CPUPercent < 0.1 {
 				break
 			}
-			fmt.Printf("   %-20s %6.1f%% %s\n",
+			if i == 0 {
+				app.shownTarget = &proc
+			}
+			fmt.Fprintf(&app.frame, "   %-20s %6.1f%% %s\n",
 				app.colorize(app.truncateString(proc.Name, 20), ColorCyan),
-				proc.CPUPercent,
+				app.procCPU(proc.CPUPercent),
 				app.colorize(app.formatMB(proc.MemoryMB), ColorDim))
 		}
-		fmt.Println()
+		fmt.Fprintln(&app.frame)
+	}
+}
+
+// staleNote marks process stats held over from an earlier scan after the
+// process list could not be read
+func (app *App) staleNote(stats *internal.ProcessStats) string {
+	if !stats.Stale {
+		return ""
 	}
+	return " " + app.colorize("(process list unreadable, showing "+stats.Timestamp.Format("15:04:05")+")", ColorYellow)
 }
 
 func (app *App) displayNetworkSummary(stats *internal.NetworkStats) {
-	fmt.Printf("%s🌐 Network Summary%s\n", app.colorize("", ColorBold+ColorGreen), app.colorize("", ColorReset))
-	fmt.Printf("   Active Interfaces: %s | Connections: %s\n",
+	fmt.Fprintf(&app.frame, "%s%s Network Summary%s\n", app.colorize("", ColorBold+ColorGreen), app.icon("🌐"), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "   Active Interfaces: %s | Connections: %s\n",
 		app.colorize(fmt.Sprintf("%d", stats.ActiveIfaces), ColorCyan),
 		app.colorize(fmt.Sprintf("%d", stats.Connections), ColorCyan))
-	fmt.Printf("   Total Traffic: ↑%s ↓%s\n\n",
+	fmt.Fprintf(&app.frame, "   Total Traffic: ↑%s ↓%s\n\n",
 		app.colorize(internal.FormatNetworkBytes(stats.TotalSent), ColorRed),
 		app.colorize(internal.FormatNetworkBytes(stats.TotalRecv), ColorGreen))
 }
 
 func (app *App) displayProcessesView() {
-	procStats, err := internal.GetProcessStats()
+	procStats, err := app.processStats()
 	if err != nil {
-		fmt.Printf(app.colorize("Error getting process stats: %v\n", ColorRed), err)
+		fmt.Fprintf(&app.frame, app.colorize("Error getting process stats: %v\n", ColorRed), err)
 		return
 	}
 
 	// Process counts
-	fmt.Printf("%s📊 Process Statistics%s\n", app.colorize("", ColorBold+ColorPurple), app.colorize("", ColorReset))
-	fmt.Printf("Total: %s | Running: %s | Sleeping: %s\n\n",
+	fmt.Fprintf(&app.frame, "%s%s Process Statistics%s%s\n", app.colorize("", ColorBold+ColorPurple), app.icon("📊"), app.colorize("", ColorReset), app.staleNote(procStats))
+	fmt.Fprintf(&app.frame, "Total: %s | Threads: %s | Running: %s | Sleeping: %s | Disk wait: %s | Stopped: %s | Zombie: %s\n\n",
 		app.colorize(fmt.Sprintf("%d", procStats.TotalProcesses), ColorCyan),
+		app.colorize(fmt.Sprintf("%d", procStats.TotalThreads), ColorCyan),
 		app.colorize(fmt.Sprintf("%d", procStats.RunningProcs), ColorGreen),
-		app.colorize(fmt.Sprintf("%d", procStats.SleepingProcs), ColorYellow))
+		app.colorize(fmt.Sprintf("%d", procStats.SleepingProcs), ColorYellow),
+		app.colorize(fmt.Sprintf("%d", procStats.DiskWaitProcs), ColorYellow),
+		app.colorize(fmt.Sprintf("%d", procStats.StoppedProcs), ColorDim),
+		app.colorize(fmt.Sprintf("%d", procStats.ZombieProcs), zombieColor(procStats.ZombieProcs)))
+
+	// Processes started or exited at the latest refresh, see
+	// trackProcessChanges
+	var added, removed []internal.ProcessInfo
+	if app.showChanges {
+		added, removed = app.ofShownUsers(app.startedProcs), app.ofShownUsers(app.exitedProcs)
+	}
+	newPIDs := make(map[int32]bool, len(added))
+	for _, proc := range added {
+		newPIDs[proc.PID] = true
+	}
+	pidLabel := func(pid int32) string {
+		if newPIDs[pid] {
+			return app.colorize(fmt.Sprintf("%-6d", pid), ColorBold+ColorGreen)
+		}
+		return fmt.Sprintf("%-6d", pid)
+	}
 
-	// Top CPU processes
-	fmt.Printf("%s🔥 Top CPU Usage:%s\n", app.colorize("", ColorBold+ColorRed), app.colorize("", ColorReset))
-	fmt.Printf("   %-6s %-25s %-12s %8s %10s\n", "PID", "Name", "User", "CPU%", "Memory")
-	fmt.Printf("   %s\n", app.colorize(strings.Repeat("─", 65), ColorDim))
+	if app.processTree {
+		app.displayProcessTree(procStats)
+		return
+	}
+	if app.aggregate {
+		app.displayProcessGroups(procStats)
+		return
+	}
 
-	limit := 10
-	if app.compactMode {
-		limit = 5
+	limit := app.topLimit()
+
+	// Optional per-process network column for the top CPU list
+	var procNet map[int32]internal.ProcessNetwork
+	showProcNet := app.showProcNet && app.localStats()
+	if showProcNet {
+		var pids []int32
+		for i, proc := range procStats.TopCPU {
+			if i >= limit {
+				break
+			}
+			pids = append(pids, proc.PID)
+		}
+		procNet, err = internal.GetProcessNetwork(app.ctx, pids)
+		if err != nil {
+			fmt.Fprintf(&app.frame, "%s\n\n", app.colorize(fmt.Sprintf("Network column unavailable: %v", err), ColorDim))
+			showProcNet = false
+		}
 	}
 
+	// Top CPU processes
+	fmt.Fprintf(&app.frame, "%s%s Top CPU Usage:%s\n", app.colorize("", ColorBold+ColorRed), app.icon("🔥"), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "   %-6s %-25s %-12s %8s %10s %10s %6s %4s", "PID", "Name", "User", app.procCPULabel(), "Memory", "Uptime", "FDs", "Nice")
+	separatorWidth := 88
+	if showProcNet {
+		fmt.Fprintf(&app.frame, " %20s", "Network")
+		separatorWidth += 21
+	}
+	fmt.Fprintln(&app.frame)
+	fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", separatorWidth), ColorDim))
+
 	for i, proc := range procStats.TopCPU {
 		if i >= limit || proc.CPUPercent < 0.1 {
 			break
 		}
-		cpuColor := app.getUsageColor(float64(proc.CPUPercent))
-		fmt.Printf("   %-6d %-25s %-12s %s%7.1f%%%s %9s\n",
-			proc.PID,
+		if i == 0 {
+			app.shownTarget = &proc
+		}
+		cpuColor := app.getUsageColor(app.procCPU(proc.CPUPercent))
+		fmt.Fprintf(&app.frame, "   %s %-25s %-12s %s%7.1f%%%s %9s %10s %6s %4s",
+			pidLabel(proc.PID),
 			app.colorize(app.truncateString(proc.Name, 25), ColorCyan),
 			app.colorize(app.truncateString(proc.Username, 12), ColorDim),
 			app.colorize("", cpuColor),
-			proc.CPUPercent,
+			app.procCPU(proc.CPUPercent),
 			app.colorize("", ColorReset),
-			app.colorize(app.formatMB(proc.MemoryMB), ColorYellow))
+			app.colorize(app.formatMB(proc.MemoryMB), ColorYellow),
+			app.colorize(app.formatProcessUptime(proc.CreateTime), ColorDim),
+			app.colorize(app.formatFDs(proc.NumFDs), app.fdColor(proc)),
+			app.colorize(fmt.Sprintf("%d", proc.Nice), niceColor(proc.Nice)))
+		if showProcNet {
+			fmt.Fprintf(&app.frame, " %20s", app.formatProcessNetwork(procNet[proc.PID]))
+		}
+		fmt.Fprintln(&app.frame)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(&app.frame)
 
 	// Top Memory processes
-	fmt.Printf("%s💾 Top Memory Usage:%s\n", app.colorize("", ColorBold+ColorBlue), app.colorize("", ColorReset))
-	fmt.Printf("   %-6s %-25s %-12s %8s %10s\n", "PID", "Name", "User", "Mem%", "Memory")
-	fmt.Printf("   %s\n", app.colorize(strings.Repeat("─", 65), ColorDim))
+	fmt.Fprintf(&app.frame, "%s%s Top Memory Usage:%s\n", app.colorize("", ColorBold+ColorBlue), app.icon("💾"), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "   %-6s %-25s %-12s %8s %10s %10s %6s %4s\n", "PID", "Name", "User", "Mem%", "Memory", "Uptime", "FDs", "Nice")
+	fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 88), ColorDim))
 
 	for i, proc := range procStats.TopMemory {
 		if i >= limit || proc.MemPercent < 0.1 {
 			break
 		}
 		memColor := app.getUsageColor(float64(proc.MemPercent))
-		fmt.Printf("   %-6d %-25s %-12s %s%7.1f%%%s %9s\n",
-			proc.PID,
+		fmt.Fprintf(&app.frame, "   %s %-25s %-12s %s%7.1f%%%s %9s %10s %6s %4s\n",
+			pidLabel(proc.PID),
 			app.colorize(app.truncateString(proc.Name, 25), ColorCyan),
 			app.colorize(app.truncateString(proc.Username, 12), ColorDim),
 			app.colorize("", memColor),
 			proc.MemPercent,
 			app.colorize("", ColorReset),
-			app.colorize(app.formatMB(proc.MemoryMB), ColorYellow))
+			app.colorize(app.formatMB(proc.MemoryMB), ColorYellow),
+			app.colorize(app.formatProcessUptime(proc.CreateTime), ColorDim),
+			app.colorize(app.formatFDs(proc.NumFDs), app.fdColor(proc)),
+			app.colorize(fmt.Sprintf("%d", proc.Nice), niceColor(proc.Nice)))
+	}
+
+	// Top disk I/O processes; rates need two scans and readable I/O counters
+	fmt.Fprintln(&app.frame)
+	fmt.Fprintf(&app.frame, "%s%s Top Disk I/O:%s\n", app.colorize("", ColorBold+ColorYellow), app.icon("💽"), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "   %-6s %-25s %-12s %12s %12s\n", "PID", "Name", "User", "Read", "Write")
+	fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 71), ColorDim))
+
+	shown := 0
+	for _, proc := range procStats.TopIO {
+		if shown >= limit || proc.ReadKBps+proc.WriteKBps < 0.1 {
+			break
+		}
+		shown++
+		fmt.Fprintf(&app.frame, "   %-6d %-25s %-12s %12s %12s\n",
+			proc.PID,
+			app.colorize(app.truncateString(proc.Name, 25), ColorCyan),
+			app.colorize(app.truncateString(proc.Username, 12), ColorDim),
+			app.colorize(internal.FormatNetworkSpeed(proc.ReadKBps), ColorGreen),
+			app.colorize(internal.FormatNetworkSpeed(proc.WriteKBps), ColorYellow))
+	}
+	if shown == 0 {
+		fmt.Fprintln(&app.frame, app.colorize("   No disk activity since the last refresh (needs permission to read I/O counters)", ColorDim))
+	}
+
+	if len(added) > 0 || len(removed) > 0 {
+		app.displayProcessChanges(added, removed, limit)
+	}
+
+	// Possible descriptor leaks anywhere in the process list
+	var leaking []internal.ProcessInfo
+	for _, proc := range procStats.AllProcesses {
+		if proc.NearFDLimit() {
+			leaking = append(leaking, proc)
+		}
+	}
+	if len(leaking) > 0 {
+		fmt.Fprintln(&app.frame)
+		for _, proc := range leaking {
+			fmt.Fprintln(&app.frame, app.colorize(fmt.Sprintf("   %s PID %d (%s) has %d open files, close to the common limit of %d",
+				app.icon("⚠"), proc.PID, proc.Name, proc.NumFDs, internal.DefaultFDLimit), ColorBold+ColorRed))
+		}
+	}
+}
+
+// displayProcessChanges lists processes that started (green) or exited
+// (struck through, shown for this one scan) since the previous scan
+func (app *App) displayProcessChanges(added, removed []internal.ProcessInfo, limit int) {
+	fmt.Fprintln(&app.frame)
+	fmt.Fprintf(&app.frame, "%s%s Started/Exited Since Last Refresh: %s %s%s\n",
+		app.colorize("", ColorBold+ColorCyan), app.icon("📄"),
+		app.colorize(fmt.Sprintf("+%d", len(added)), ColorGreen),
+		app.colorize(fmt.Sprintf("-%d", len(removed)), ColorRed),
+		app.colorize("", ColorReset))
+
+	for i, proc := range added {
+		if i >= limit {
+			fmt.Fprintln(&app.frame, app.colorize(fmt.Sprintf("   ... and %d more started", len(added)-limit), ColorDim))
+			break
+		}
+		fmt.Fprintln(&app.frame, app.colorize(fmt.Sprintf("   + %-6d %-25s %s", proc.PID, app.truncateString(proc.Name, 25), app.truncateString(proc.Username, 12)), ColorGreen))
+	}
+	for i, proc := range removed {
+		if i >= limit {
+			fmt.Fprintln(&app.frame, app.colorize(fmt.Sprintf("   ... and %d more exited", len(removed)-limit), ColorDim))
+			break
+		}
+		fmt.Fprintln(&app.frame, app.colorize(fmt.Sprintf("   - %-6d %-25s %s", proc.PID, app.truncateString(proc.Name, 25), app.truncateString(proc.Username, 12)), ColorDim+ColorStrike))
+	}
+}
+
+// trackProcessChanges finds the processes started or exited since the
+// previous refresh. It compares every process, not the -user selection, so
+// changing the filter doesn't show processes as started or exited; the
+// first scan has nothing to compare against.
+func (app *App) trackProcessChanges() {
+	if !app.showChanges {
+		return
+	}
+	procStats, err := app.collector.ProcessStats(app.ctx)
+	if err != nil {
+		return
+	}
+	if app.prevProcesses != nil {
+		app.startedProcs, app.exitedProcs = diffProcesses(app.prevProcesses, procStats.AllProcesses)
+	}
+	app.prevProcesses = procStats.AllProcesses
+}
+
+// ofShownUsers returns the processes in procs that pass the -user filter
+func (app *App) ofShownUsers(procs []internal.ProcessInfo) []internal.ProcessInfo {
+	if len(app.userFilter) == 0 {
+		return procs
+	}
+	var shown []internal.ProcessInfo
+	for _, proc := range procs {
+		if matchesUser(app.userFilter, proc.Username) {
+			shown = append(shown, proc)
+		}
+	}
+	return shown
+}
+
+// diffProcesses returns the processes in cur but not prev, and those in
+// prev but not cur. A process is identified by PID and start time, so a
+// reused PID counts as one exit and one start.
+func diffProcesses(prev, cur []internal.ProcessInfo) (added, removed []internal.ProcessInfo) {
+	seen := make(map[processKey]bool, len(prev))
+	for _, proc := range prev {
+		seen[processKey{proc.PID, proc.CreateTime}] = true
+	}
+	current := make(map[processKey]bool, len(cur))
+	for _, proc := range cur {
+		key := processKey{proc.PID, proc.CreateTime}
+		current[key] = true
+		if !seen[key] {
+			added = append(added, proc)
+		}
+	}
+	for _, proc := range prev {
+		if !current[processKey{proc.PID, proc.CreateTime}] {
+			removed = append(removed, proc)
+		}
+	}
+	return added, removed
+}
+
+// displayProcessGroups lists processes aggregated by name, busiest first
+func (app *App) displayProcessGroups(stats *internal.ProcessStats) {
+	limit := 20
+	if app.compactMode {
+		limit = 10
+	}
+
+	fmt.Fprintf(&app.frame, "%s%s Processes by Name:%s\n", app.colorize("", ColorBold+ColorRed), app.icon("🔥"), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "   %-25s %9s %8s %8s %10s\n", "Name", "Instances", app.procCPULabel(), "Mem%", "Memory")
+	fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 64), ColorDim))
+
+	for i, group := range internal.AggregateByName(stats.AllProcesses) {
+		if i >= limit {
+			break
+		}
+		fmt.Fprintf(&app.frame, "   %-25s %9d %s%7.1f%%%s %7.1f%% %10s\n",
+			app.colorize(app.truncateString(group.Name, 25), ColorCyan),
+			group.Count,
+			app.colorize("", app.getUsageColor(app.procCPU(group.CPUPercent))),
+			app.procCPU(group.CPUPercent),
+			app.colorize("", ColorReset),
+			group.MemPercent,
+			app.colorize(app.formatMB(group.MemoryMB), ColorYellow))
+	}
+}
+
+// displayProcessTree shows every process indented under its parent. When
+// collapsed, only top-level processes and their direct children are listed.
+func (app *App) displayProcessTree(stats *internal.ProcessStats) {
+	root := internal.BuildProcessTree(stats.AllProcesses)
+
+	maxRows := 40
+	if app.compactMode {
+		maxRows = 20
+	}
+
+	title := "Process Tree"
+	if app.treeCollapsed {
+		title += " (collapsed)"
+	}
+	fmt.Fprintf(&app.frame, "%s%s %s:%s\n", app.colorize("", ColorBold+ColorPurple), app.icon("🌳"), title, app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "   %-6s %8s %10s  %s\n", "PID", app.procCPULabel(), "Memory", "Command")
+	fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 76), ColorDim))
+
+	rows, truncated := 0, false
+	var walk func(node *internal.ProcessNode, prefix string, depth int)
+	walk = func(node *internal.ProcessNode, prefix string, depth int) {
+		for i, child := range node.Children {
+			if rows >= maxRows {
+				truncated = true
+				return
+			}
+			rows++
+
+			connector, indent := "├─ ", "│  "
+			if i == len(node.Children)-1 {
+				connector, indent = "└─ ", "   "
+			}
+
+			proc := child.Process
+			name := app.colorize(proc.Name, ColorCyan)
+			collapsed := app.treeCollapsed && depth >= 1 && len(child.Children) > 0
+			if collapsed {
+				name += app.colorize(fmt.Sprintf(" [+%d]", child.Descendants()), ColorDim)
+			}
+
+			fmt.Fprintf(&app.frame, "   %-6d %s%7.1f%%%s %9s  %s%s\n",
+				proc.PID,
+				app.colorize("", app.getUsageColor(app.procCPU(proc.CPUPercent))),
+				app.procCPU(proc.CPUPercent),
+				app.colorize("", ColorReset),
+				app.colorize(app.formatMB(proc.MemoryMB), ColorYellow),
+				app.colorize(prefix+connector, ColorDim),
+				name)
+
+			if !collapsed {
+				walk(child, prefix+indent, depth+1)
+			}
+		}
+	}
+	walk(root, "", 0)
+
+	if truncated {
+		fmt.Fprintf(&app.frame, "   %s\n", app.colorize(fmt.Sprintf("... limited to %d rows, press X to collapse", maxRows), ColorDim))
 	}
 }
 
 func (app *App) displayNetworkView() {
-	netStats, err := internal.GetNetworkStats()
+	netStats, err := app.collector.NetworkStats(app.ctx)
 	if err != nil {
-		fmt.Printf(app.colorize("Error getting network stats: %v\n", ColorRed), err)
+		fmt.Fprintf(&app.frame, app.colorize("Error getting network stats: %v\n", ColorRed), err)
 		return
 	}
+	if app.netBaseline == nil {
+		app.netBaseline = internal.NewTrafficBaseline(netStats)
+	}
+	app.netBaseline.Apply(netStats)
 
-	netSpeeds, _ := internal.GetNetworkSpeeds()
+	netSpeeds := app.netSpeeds
 
 	// Network summary
-	fmt.Printf("%s🌐 Network Overview%s\n", app.colorize("", ColorBold+ColorGreen), app.colorize("", ColorReset))
-	fmt.Printf("Active Interfaces: %s | Connections: %s\n",
+	fmt.Fprintf(&app.frame, "%s%s Network Overview%s\n", app.colorize("", ColorBold+ColorGreen), app.icon("🌐"), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "Active Interfaces: %s | Connections: %s\n",
 		app.colorize(fmt.Sprintf("%d", netStats.ActiveIfaces), ColorCyan),
 		app.colorize(fmt.Sprintf("%d", netStats.Connections), ColorCyan))
-	fmt.Printf("Total Traffic: ↑%s ↓%s\n\n",
+	fmt.Fprintf(&app.frame, "Total Traffic: ↑%s ↓%s\n",
 		app.colorize(internal.FormatNetworkBytes(netStats.TotalSent), ColorRed),
 		app.colorize(internal.FormatNetworkBytes(netStats.TotalRecv), ColorGreen))
+	fmt.Fprintf(&app.frame, "Since %s: ↑%s ↓%s\n\n",
+		app.netBaseline.Since.Format("15:04:05"),
+		app.colorize(internal.FormatNetworkBytes(netStats.SessionSent), ColorRed),
+		app.colorize(internal.FormatNetworkBytes(netStats.SessionRecv), ColorGreen))
+
+	// Connection states
+	fmt.Fprintf(&app.frame, "%s%s Connections by State:%s\n", app.colorize("", ColorBold+ColorCyan), app.icon("🔗"), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "   %-6s %8s %12s %10s %11s %6s %7s\n", "Proto", "LISTEN", "ESTABLISHED", "TIME_WAIT", "CLOSE_WAIT", "SYN_*", "Other")
+	fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 66), ColorDim))
+	app.displayConnectionCounts("TCP", netStats.Breakdown.TCP)
+	// UDP is connectionless, so its sockets have no state to break down
+	fmt.Fprintf(&app.frame, "   %s %d\n\n", app.colorize("UDP sockets:", ColorCyan), netStats.Breakdown.UDP)
 
 	// Current speeds
 	if len(netSpeeds) > 0 {
-		fmt.Printf("%s📊 Current Network Activity:%s\n", app.colorize("", ColorBold+ColorBlue), app.colorize("", ColorReset))
-		fmt.Printf("   %-20s %15s %15s %15s\n", "Interface", "Upload", "Download", "Total")
-		fmt.Printf("   %s\n", app.colorize(strings.Repeat("─", 70), ColorDim))
+		fmt.Fprintf(&app.frame, "%s%s Current Network Activity:%s\n", app.colorize("", ColorBold+ColorBlue), app.icon("📊"), app.colorize("", ColorReset))
+		fmt.Fprintf(&app.frame, "   %-20s %15s %15s %15s\n", "Interface", "Upload", "Download", "Total")
+		fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 70), ColorDim))
 
 		for i, speed := range netSpeeds {
 			if i >= 5 {
 				break
 			}
-			totalSpeed := speed.UploadKBps + speed.DownloadKBps
-			fmt.Printf("   %-20s %15s %15s %15s\n",
+			upload, download := speed.UploadKBps, speed.DownloadKBps
+			if app.netSmoothing > 0 {
+				upload, download = speed.SmoothedUploadKBps, speed.SmoothedDownloadKBps
+			}
+			totalSpeed := upload + download
+			fmt.Fprintf(&app.frame, "   %-20s %15s %15s %15s\n",
 				app.colorize(app.truncateString(speed.Interface, 20), ColorCyan),
-				app.colorize(internal.FormatNetworkSpeed(speed.UploadKBps), ColorRed),
-				app.colorize(internal.FormatNetworkSpeed(speed.DownloadKBps), ColorGreen),
+				app.colorize(internal.FormatNetworkSpeed(upload), ColorRed),
+				app.colorize(internal.FormatNetworkSpeed(download), ColorGreen),
 				app.colorize(internal.FormatNetworkSpeed(totalSpeed), ColorYellow))
 		}
-		fmt.Println()
+		fmt.Fprintln(&app.frame)
 	}
 
 	// Interface statistics
 	topInterfaces := internal.GetTopNetworkInterfaces(netStats.Interfaces, 8)
 	if len(topInterfaces) > 0 {
-		fmt.Printf("%s📈 Network Interfaces (Total Traffic):%s\n", app.colorize("", ColorBold+ColorPurple), app.colorize("", ColorReset))
-		fmt.Printf("   %-20s %-15s %-15s %8s\n", "Interface", "Sent", "Received", "Status")
-		fmt.Printf("   %s\n", app.colorize(strings.Repeat("─", 65), ColorDim))
+		fmt.Fprintf(&app.frame, "%s%s Network Interfaces (Total Traffic):%s\n", app.colorize("", ColorBold+ColorPurple), app.icon("📈"), app.colorize("", ColorReset))
+		fmt.Fprintf(&app.frame, "   %-12s %-15s %-12s %-12s %-12s %s\n", "Interface", "IPv4", "Sent", "Received", "Session", "Status")
+		fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 74), ColorDim))
 
 		for _, iface := range topInterfaces {
 			statusColor := ColorRed
@@ -419,31 +1800,179 @@ func (app *App) displayNetworkView() {
 				statusColor = ColorGreen
 			}
 
-			fmt.Printf("   %-20s %-15s %-15s %s\n",
-				app.colorize(app.truncateString(iface.Name, 20), ColorCyan),
+			fmt.Fprintf(&app.frame, "   %-12s %-15s %-12s %-12s %-12s %s\n",
+				app.colorize(app.truncateString(iface.Name, 12), ColorCyan),
+				app.colorize(iface.PrimaryIPv4(), ColorDim),
 				app.colorize(internal.FormatNetworkBytes(iface.BytesSent), ColorRed),
 				app.colorize(internal.FormatNetworkBytes(iface.BytesRecv), ColorGreen),
+				app.colorize(internal.FormatNetworkBytes(iface.SessionSent+iface.SessionRecv), ColorYellow),
 				app.colorize(status, statusColor))
+			if iface.IsWireless {
+				fmt.Fprintf(&app.frame, "     Signal: %s\n", app.signalStrength(iface))
+			}
+		}
+	}
+
+	// Listening ports
+	// Listening ports are not recorded, so a replay leaves them out rather
+	// than mixing in live values
+	var ports []internal.ListenPort
+	if app.localStats() {
+		ports, err = internal.GetListeningPorts(app.ctx)
+	}
+	if err == nil && len(ports) > 0 {
+		fmt.Fprintln(&app.frame)
+		fmt.Fprintf(&app.frame, "%s%s Listening Ports:%s\n", app.colorize("", ColorBold+ColorYellow), app.icon("🔌"), app.colorize("", ColorReset))
+		fmt.Fprintf(&app.frame, "   %-6s %-22s %6s %-8s %s\n", "Proto", "Address", "Port", "PID", "Process")
+		fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 65), ColorDim))
+
+		limit := 10
+		if app.compactMode {
+			limit = 5
+		}
+
+		for i, port := range ports {
+			if i >= limit {
+				break
+			}
+			pid := ""
+			if port.PID > 0 {
+				pid = fmt.Sprintf("%d", port.PID)
+			}
+			fmt.Fprintf(&app.frame, "   %-6s %-22s %6d %-8s %s\n",
+				port.Protocol,
+				app.colorize(app.truncateString(port.Address, 22), ColorCyan),
+				port.Port,
+				pid,
+				app.colorize(app.truncateString(port.ProcessName, 25), ColorYellow))
+		}
+	}
+
+	// Established connections, like listening ports, are read live
+	if app.localStats() {
+		app.displayConnections()
+	}
+}
+
+// displayConnections lists established connections, with remote hostnames
+// in place of addresses once resolved when resolveHosts is on
+func (app *App) displayConnections() {
+	conns, err := internal.GetEstablishedConnections(app.ctx)
+	if err != nil || len(conns) == 0 {
+		return
+	}
+	fmt.Fprintln(&app.frame)
+	title := "Established Connections:"
+	if app.resolveHosts {
+		title = "Established Connections (hostnames):"
+	}
+	fmt.Fprintf(&app.frame, "%s%s %s%s\n", app.colorize("", ColorBold+ColorCyan), app.icon("🔗"), title, app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "   %-6s %6s %-40s %-8s %s\n", "Proto", "Local", "Remote", "PID", "Process")
+	fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 80), ColorDim))
+
+	limit := 10
+	if app.compactMode {
+		limit = 5
+	}
+	for i, conn := range conns {
+		if i >= limit {
+			fmt.Fprintf(&app.frame, "   %s\n", app.colorize(fmt.Sprintf("... and %d more", len(conns)-limit), ColorDim))
+			break
+		}
+		remote := conn.RemoteAddr
+		if app.resolveHosts {
+			if name, _ := app.resolver.Lookup(conn.RemoteAddr); name != "" {
+				remote = name
+			}
+		}
+		// Keep the port visible when a long hostname is cut short
+		port := fmt.Sprintf(":%d", conn.RemotePort)
+		remote = app.truncateString(remote, 40-len(port)) + port
+		pid := ""
+		if conn.PID > 0 {
+			pid = fmt.Sprintf("%d", conn.PID)
+		}
+		fmt.Fprintf(&app.frame, "   %-6s %6d %s %-8s %s\n",
+			conn.Protocol,
+			conn.LocalPort,
+			app.colorize(fmt.Sprintf("%-40s", remote), ColorCyan),
+			pid,
+			app.colorize(app.truncateString(conn.ProcessName, 20), ColorYellow))
+	}
+}
+
+func (app *App) displayConnectionCounts(proto string, counts internal.ConnectionStateCounts) {
+	// TIME_WAIT and CLOSE_WAIT pile-ups are worth drawing attention to
+	waitColor := func(n int) string {
+		if n > 0 {
+			return ColorYellow
 		}
+		return ColorDim
 	}
+
+	fmt.Fprintf(&app.frame, "   %-6s %8d %12d %s %s %6d %7d\n",
+		app.colorize(proto, ColorCyan),
+		counts.Listen,
+		counts.Established,
+		app.colorize(fmt.Sprintf("%10d", counts.TimeWait), waitColor(counts.TimeWait)),
+		app.colorize(fmt.Sprintf("%11d", counts.CloseWait), waitColor(counts.CloseWait)),
+		counts.Syn,
+		counts.Other)
 }
 
 func (app *App) displayDisksView() {
-	stats, err := internal.GetSystemStats()
+	stats, err := app.collector.SystemStats(app.ctx)
 	if err != nil {
-		fmt.Printf(app.colorize("Error getting system stats: %v\n", ColorRed), err)
+		fmt.Fprintf(&app.frame, app.colorize("Error getting system stats: %v\n", ColorRed), err)
 		return
 	}
 
-	fmt.Printf("%s💽 Disk Usage Details%s\n", app.colorize("", ColorBold+ColorBlue), app.colorize("", ColorReset))
-	fmt.Printf("   %-20s %-10s %-12s %-12s %-12s %s\n", "Device", "Usage", "Used", "Free", "Total", "Mount Point")
-	fmt.Printf("   %s\n", app.colorize(strings.Repeat("─", 90), ColorDim))
+	fmt.Fprintf(&app.frame, "%s%s Disk Usage Details%s %s\n", app.colorize("", ColorBold+ColorBlue), app.icon("💽"), app.colorize("", ColorReset),
+		app.colorize("(sorted by "+diskSortNames[app.diskSort]+", O to change)", ColorDim))
+	if !app.sectionAvailable(stats, "disk") {
+		return
+	}
+	fmt.Fprintf(&app.frame, "   %-20s %-10s %-12s %-12s %-12s %-8s %s\n", "Device", "Usage", "Used", "Free", "Total", "Inodes", "Mount Point")
+	fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 99), ColorDim))
 
-	for _, disk := range stats.Disk {
+	// Mounts are only inspected on this machine
+	selectable := app.localStats()
+	for i, disk := range app.visibleDisks(stats.Disk) {
 		device := app.truncateString(filepath.Base(disk.Device), 20)
 		usageColor := app.getUsageColor(disk.UsedPercent)
+		mountColor := ColorPurple
+		if disk.ReadOnly {
+			mountColor = ColorBold + ColorRed
+		}
+		marker := "   "
+		if selectable && i == app.diskSelected {
+			marker = app.colorize(" > ", ColorBold+ColorYellow)
+		}
 
-		fmt.Printf("   %-20s %s%9.1f%%%s %-12s %-12s %-12s %s\n",
+		// A stale network mount has no usage to show
+		if disk.Unresponsive {
+			fmt.Fprintf(&app.frame, "%s%-20s %s %s\n", marker,
+				app.colorize(device, ColorCyan),
+				app.colorize(fmt.Sprintf("%-58s", "not responding"), ColorBold+ColorRed),
+				app.colorize(app.truncateString(disk.Mountpoint, 20), mountColor))
+			continue
+		}
+
+		// Filesystems without inode accounting (vfat, NTFS) report zero
+		inodes := "-"
+		inodeColor := ColorDim
+		if disk.InodesTotal > 0 {
+			inodes = fmt.Sprintf("%.1f%%", disk.InodesUsedPercent)
+			inodeColor = app.getUsageColor(disk.InodesUsedPercent)
+		}
+
+		// Running out of inodes while bytes are plentiful is easy to miss
+		inodesExhausted := disk.InodesTotal > 0 && disk.InodesUsedPercent > 90 && disk.UsedPercent < 60
+		if inodesExhausted {
+			inodeColor = ColorBold + ColorRed
+		}
+
+		fmt.Fprintf(&app.frame, "%s%-20s %s%9.1f%%%s %-12s %-12s %-12s %-8s %s\n", marker,
 			app.colorize(device, ColorCyan),
 			app.colorize("", usageColor),
 			disk.UsedPercent,
@@ -451,60 +1980,366 @@ func (app *App) displayDisksView() {
 			app.colorize(internal.FormatBytes(disk.Used), ColorYellow),
 			app.colorize(internal.FormatBytes(disk.Free), ColorGreen),
 			app.colorize(internal.FormatBytes(disk.Total), ColorDim),
-			app.colorize(app.truncateString(disk.Mountpoint, 20), ColorPurple))
+			app.colorize(inodes, inodeColor),
+			app.colorize(app.truncateString(disk.Mountpoint, 20), mountColor))
+
+		if disk.ReadOnly {
+			fmt.Fprintf(&app.frame, "   %20s %s\n", "", app.colorize(
+				fmt.Sprintf("%s Mounted read-only (%s)", app.icon("⚠"), app.truncateString(strings.Join(disk.Opts, ","), 50)), ColorBold+ColorRed))
+		}
+
+		if inodesExhausted {
+			fmt.Fprintf(&app.frame, "   %20s %s\n", "", app.colorize(
+				fmt.Sprintf("%s Inodes nearly exhausted (%d free) although %.1f%% of space is free",
+					app.icon("⚠"), disk.InodesFree, 100-disk.UsedPercent), ColorBold+ColorRed))
+		}
+
+		if eta, ok := app.diskTimeToFull(disk.Mountpoint); ok {
+			etaColor := ColorYellow
+			if eta < 24*time.Hour {
+				etaColor = ColorBold + ColorRed
+			}
+			fmt.Fprintf(&app.frame, "   %20s %s\n", "", app.colorize(
+				fmt.Sprintf("Full in ~%s at the current growth rate", internal.FormatUptime(uint64(eta.Seconds()))), etaColor))
+		}
 
 		// Progress bar for each disk
 		if !app.compactMode {
-			fmt.Printf("   %20s %s\n", "", app.getProgressBar(disk.UsedPercent, 50, usageColor))
+			fmt.Fprintf(&app.frame, "   %20s %s\n", "", app.getProgressBar(disk.UsedPercent, 50, usageColor))
+		}
+	}
+
+	if selectable {
+		fmt.Fprintln(&app.frame, app.colorize("   ↑/↓ select a filesystem, Enter lists the processes using it", ColorDim))
+	}
+	if app.mountUsers != nil {
+		app.displayMountUsers()
+	}
+
+	if app.localStats() {
+		app.displayDiskIO()
+	}
+}
+
+// displayDiskIO lists the busiest block devices. Utilization near 100%
+// with modest throughput points to a saturated or throttled disk.
+func (app *App) displayDiskIO() {
+	fmt.Fprintln(&app.frame)
+	fmt.Fprintf(&app.frame, "%s%s Disk I/O:%s\n", app.colorize("", ColorBold+ColorYellow), app.icon("📊"), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "   %-20s %12s %12s %8s %8s\n", "Device", "Read", "Write", "Util%", "Queue")
+	fmt.Fprintf(&app.frame, "   %s\n", app.colorize(strings.Repeat("─", 64), ColorDim))
+
+	limit := 10
+	if app.compactMode {
+		limit = 5
+	}
+	if len(app.diskIO) == 0 {
+		fmt.Fprintln(&app.frame, app.colorize("   No disk activity since the last refresh", ColorDim))
+	}
+	for i, speed := range app.diskIO {
+		if i >= limit {
+			break
 		}
+		fmt.Fprintf(&app.frame, "   %-20s %12s %12s %s %s\n",
+			app.colorize(app.truncateString(speed.Device, 20), ColorCyan),
+			app.colorize(internal.FormatNetworkSpeed(speed.ReadKBps), ColorGreen),
+			app.colorize(internal.FormatNetworkSpeed(speed.WriteKBps), ColorYellow),
+			app.colorize(fmt.Sprintf("%7.1f%%", speed.UtilPercent), app.getUsageColor(speed.UtilPercent)),
+			app.colorize(fmt.Sprintf("%8.2f", speed.QueueDepth), ColorDim))
 	}
 }
 
 func (app *App) displaySystemView() {
-	stats, err := internal.GetSystemStats()
+	stats, err := app.collector.SystemStats(app.ctx)
 	if err != nil {
-		fmt.Printf(app.colorize("Error getting system stats: %v\n", ColorRed), err)
+		fmt.Fprintf(&app.frame, app.colorize("Error getting system stats: %v\n", ColorRed), err)
 		return
 	}
 
 	// Detailed system information
-	fmt.Printf("%s🖥️  Detailed System Information%s\n", app.colorize("", ColorBold+ColorBlue), app.colorize("", ColorReset))
-	fmt.Printf("   Hostname:      %s\n", app.colorize(stats.Host.Hostname, ColorCyan))
-	fmt.Printf("   Operating System: %s\n", app.colorize(stats.Host.OS, ColorCyan))
-	fmt.Printf("   Platform:      %s\n", app.colorize(stats.Host.Platform, ColorCyan))
-	fmt.Printf("   Kernel Version: %s\n", app.colorize(stats.Host.KernelVersion, ColorCyan))
-	fmt.Printf("   System Uptime: %s\n\n", app.colorize(internal.FormatUptime(stats.Host.Uptime), ColorGreen))
+	if app.sectionAvailable(stats, "host") {
+		fmt.Fprintf(&app.frame, "%s%s Detailed System Information%s\n", app.colorize("", ColorBold+ColorBlue), app.icon("🖥️"), app.colorize("", ColorReset))
+		fmt.Fprintf(&app.frame, "   Hostname:      %s\n", app.colorize(stats.Host.Hostname, ColorCyan))
+		fmt.Fprintf(&app.frame, "   Operating System: %s\n", app.colorize(stats.Host.OS, ColorCyan))
+		fmt.Fprintf(&app.frame, "   Platform:      %s\n", app.colorize(stats.Host.Platform, ColorCyan))
+		fmt.Fprintf(&app.frame, "   Kernel Version: %s\n", app.colorize(stats.Host.KernelVersion, ColorCyan))
+		if stats.Host.VirtRole == "guest" && stats.Host.VirtSystem != "" {
+			fmt.Fprintf(&app.frame, "   Running in:    %s\n", app.colorize(stats.Host.VirtSystem, ColorYellow))
+			if stats.Host.InContainer() {
+				fmt.Fprintf(&app.frame, "   %s\n", app.colorize("Container: CPU and memory figures are the host's; cgroup limits may apply", ColorDim))
+			}
+		}
+		fmt.Fprintf(&app.frame, "   System Uptime: %s\n", app.colorize(internal.FormatUptime(stats.Host.Uptime), ColorGreen))
+		fmt.Fprintf(&app.frame, "   Booted:        %s\n\n", app.colorize(internal.FormatBootTime(stats.Host.BootTime), ColorGreen))
+	}
 
 	// Detailed CPU information
-	fmt.Printf("%s🔧 CPU Information%s\n", app.colorize("", ColorBold+ColorRed), app.colorize("", ColorReset))
-	fmt.Printf("   Model:         %s\n", app.colorize(stats.CPU.ModelName, ColorCyan))
-	fmt.Printf("   Logical Cores: %s\n", app.colorize(fmt.Sprintf("%d", stats.CPU.Cores), ColorYellow))
-	fmt.Printf("   Current Usage: %s%.1f%%%s\n\n",
-		app.colorize("", app.getUsageColor(stats.CPU.Usage)),
-		stats.CPU.Usage,
-		app.colorize("", ColorReset))
+	if app.sectionAvailable(stats, "cpu") {
+		fmt.Fprintf(&app.frame, "%s%s CPU Information%s\n", app.colorize("", ColorBold+ColorRed), app.icon("🔧"), app.colorize("", ColorReset))
+		fmt.Fprintf(&app.frame, "   Model:         %s\n", app.colorize(stats.CPU.ModelName, ColorCyan))
+		fmt.Fprintf(&app.frame, "   Cores:         %s\n", app.colorize(fmt.Sprintf("%d cores / %d threads", stats.CPU.PhysicalCores, stats.CPU.Cores), ColorYellow))
+		if freq := formatCPUFrequency(stats.CPU); freq != "" {
+			fmt.Fprintf(&app.frame, "   Frequency:     %s\n", app.colorize(freq, ColorYellow))
+		}
+		fmt.Fprintf(&app.frame, "   Current Usage: %s%.1f%%%s\n",
+			app.colorize("", app.getUsageColor(stats.CPU.Usage)),
+			stats.CPU.Usage,
+			app.colorize("", ColorReset))
+		fmt.Fprintf(&app.frame, "   CPU Time:      %s\n", app.getCPUTimeBar(stats.CPU, 40))
+		legend := ""
+		for _, segment := range cpuSegments(stats.CPU) {
+			key := segment.char
+			if app.colorEnabled && app.barStyle != BarASCII {
+				key = "█"
+			}
+			legend += fmt.Sprintf("%s %s %.1f%%  ", app.colorize(key, segment.color), segment.name, segment.percent)
+		}
+		fmt.Fprintf(&app.frame, "                  %s%s idle %.1f%%\n", legend, app.colorize("░", ColorDim), stats.CPU.IdlePercent)
+		// A hypervisor handing our CPU time to other guests
+		if stats.CPU.StealPercent > 10 {
+			fmt.Fprintf(&app.frame, "   %s\n", app.colorize(
+				fmt.Sprintf("%s High steal time (%.1f%%): the host is busy with other VMs", app.icon("⚠"), stats.CPU.StealPercent), ColorBold+ColorRed))
+		}
+		if stats.CPU.CgroupCPUQuota > 0 {
+			fmt.Fprintf(&app.frame, "   Cgroup Quota:  %s (%s used)\n",
+				app.colorize(fmt.Sprintf("%.1f CPUs", stats.CPU.CgroupCPUQuota), ColorCyan),
+				app.colorize(fmt.Sprintf("%.1f%%", stats.CPU.CgroupUsage), app.getUsageColor(stats.CPU.CgroupUsage)))
+		}
+		fmt.Fprintln(&app.frame)
+	}
 
 	// Detailed memory information
-	fmt.Printf("%s💾 Memory Information%s\n", app.colorize("", ColorBold+ColorBlue), app.colorize("", ColorReset))
-	fmt.Printf("   Total:         %s\n", app.colorize(internal.FormatBytes(stats.Memory.Total), ColorCyan))
-	fmt.Printf("   Used:          %s (%.1f%%)\n",
-		app.colorize(internal.FormatBytes(stats.Memory.Used), ColorYellow),
-		stats.Memory.UsedPercent)
-	fmt.Printf("   Available:     %s\n", app.colorize(internal.FormatBytes(stats.Memory.Available), ColorGreen))
-	fmt.Printf("   Free:          %s\n", app.colorize(internal.FormatBytes(stats.Memory.Free), ColorGreen))
-	fmt.Printf("   Buffers:       %s\n", app.colorize(internal.FormatBytes(stats.Memory.Buffers), ColorDim))
-	fmt.Printf("   Cached:        %s\n\n", app.colorize(internal.FormatBytes(stats.Memory.Cached), ColorDim))
+	if app.sectionAvailable(stats, "memory") {
+		fmt.Fprintf(&app.frame, "%s%s Memory Information%s\n", app.colorize("", ColorBold+ColorBlue), app.icon("💾"), app.colorize("", ColorReset))
+		fmt.Fprintf(&app.frame, "   Total:         %s\n", app.colorize(internal.FormatBytes(stats.Memory.Total), ColorCyan))
+		fmt.Fprintf(&app.frame, "   Used:          %s (%.1f%%)\n",
+			app.colorize(internal.FormatBytes(stats.Memory.Used), ColorYellow),
+			stats.Memory.UsedPercent)
+		fmt.Fprintf(&app.frame, "   Available:     %s\n", app.colorize(internal.FormatBytes(stats.Memory.Available), ColorGreen))
+		fmt.Fprintf(&app.frame, "   Free:          %s\n", app.colorize(internal.FormatBytes(stats.Memory.Free), ColorGreen))
+		fmt.Fprintf(&app.frame, "   Buffers:       %s\n", app.colorize(internal.FormatBytes(stats.Memory.Buffers), ColorDim))
+		fmt.Fprintf(&app.frame, "   Cached:        %s\n", app.colorize(internal.FormatBytes(stats.Memory.Cached), ColorDim))
+		// Only reported on Linux
+		if stats.Memory.Shared > 0 {
+			fmt.Fprintf(&app.frame, "   Shared:        %s\n", app.colorize(internal.FormatBytes(stats.Memory.Shared), ColorDim))
+		}
+		if stats.Memory.Slab > 0 {
+			fmt.Fprintf(&app.frame, "   Slab:          %s (%s reclaimable)\n",
+				app.colorize(internal.FormatBytes(stats.Memory.Slab), ColorDim),
+				app.colorize(internal.FormatBytes(stats.Memory.SReclaimable), ColorGreen))
+		}
+		if stats.Memory.Dirty > 0 {
+			fmt.Fprintf(&app.frame, "   Dirty:         %s\n", app.colorize(internal.FormatBytes(stats.Memory.Dirty), ColorDim))
+		}
+		if stats.Memory.SwapTotal > 0 {
+			fmt.Fprintf(&app.frame, "   Swap:          %s of %s (%.1f%%)\n",
+				app.colorize(internal.FormatBytes(stats.Memory.SwapUsed), app.getUsageColor(stats.Memory.SwapUsedPercent)),
+				app.colorize(internal.FormatBytes(stats.Memory.SwapTotal), ColorCyan),
+				stats.Memory.SwapUsedPercent)
+		}
+		fmt.Fprintf(&app.frame, "   Pressure:      %s\n", app.colorize(fmt.Sprintf("%.0f%%", stats.Memory.Pressure), app.getUsageColor(stats.Memory.Pressure)))
+		if stats.Memory.CgroupMemLimit > 0 {
+			fmt.Fprintf(&app.frame, "   Cgroup Limit:  %s (%s used, %.1f%%)\n",
+				app.colorize(internal.FormatBytes(stats.Memory.CgroupMemLimit), ColorCyan),
+				app.colorize(internal.FormatBytes(stats.Memory.CgroupMemUsed), ColorYellow),
+				stats.Memory.CgroupUsedPercent)
+		}
+		fmt.Fprintln(&app.frame)
+	}
+
+	// GPU information, only shown when a supported GPU is present or
+	// nvidia-smi fails. GPU stats are not recorded.
+	if !app.localStats() {
+		return
+	}
+	app.queryGPUs()
+	if app.gpuErr != nil {
+		fmt.Fprintf(&app.frame, "%s%s GPU Information%s\n", app.colorize("", ColorBold+ColorPurple), app.icon("🎮"), app.colorize("", ColorReset))
+		fmt.Fprintf(&app.frame, "   %s\n\n", app.colorize(app.truncateString("GPU stats unavailable: "+app.gpuErr.Error(), 76), ColorRed))
+	} else if len(app.gpus) > 0 {
+		fmt.Fprintf(&app.frame, "%s%s GPU Information%s\n", app.colorize("", ColorBold+ColorPurple), app.icon("🎮"), app.colorize("", ColorReset))
+		for _, g := range app.gpus {
+			fmt.Fprintf(&app.frame, "   GPU %d:         %s\n", g.Index, app.colorize(g.Name, ColorCyan))
+			fmt.Fprintf(&app.frame, "   Utilization:   %s%.1f%%%s %s\n",
+				app.colorize("", app.getUsageColor(g.UtilizationPercent)),
+				g.UtilizationPercent,
+				app.colorize("", ColorReset),
+				app.getProgressBar(g.UtilizationPercent, 20, app.getUsageColor(g.UtilizationPercent)))
+			fmt.Fprintf(&app.frame, "   Memory:        %s / %s (%.1f%%)\n",
+				app.colorize(app.formatMB(g.MemoryUsedMB), ColorYellow),
+				app.colorize(app.formatMB(g.MemoryTotalMB), ColorCyan),
+				g.MemoryUsedPercent())
+			fmt.Fprintf(&app.frame, "   Temperature:   %s\n\n", app.colorize(internal.FormatTemperature(g.TemperatureC, app.tempUnit), ColorYellow))
+		}
+	}
+}
+
+func (app *App) displayProcessView() {
+	fmt.Fprintf(&app.frame, "%s%s Process %d%s\n", app.colorize("", ColorBold+ColorPurple), app.icon("📄"), app.watcher.PID(), app.colorize("", ColorReset))
+	fmt.Fprintln(&app.frame, app.colorize(strings.Repeat("─", 80), ColorDim))
+
+	if app.watchedExited {
+		fmt.Fprintf(&app.frame, "   %s\n\n", app.colorize("Process exited", ColorBold+ColorRed))
+	}
+	proc := app.watched
+	if proc == nil {
+		if !app.watchedExited {
+			fmt.Fprintln(&app.frame, app.colorize("   Waiting for the first sample...", ColorDim))
+		}
+		return
+	}
+	if app.watchedExited {
+		fmt.Fprintln(&app.frame, app.colorize("   Last sample:", ColorDim))
+	}
+
+	cpuColor := app.getUsageColor(app.procCPU(proc.CPUPercent))
+	fmt.Fprintf(&app.frame, "   Name:          %s\n", app.colorize(proc.Name, ColorCyan))
+	fmt.Fprintf(&app.frame, "   User:          %s\n", app.colorize(proc.Username, ColorCyan))
+	fmt.Fprintf(&app.frame, "   Status:        %s\n", app.colorize(proc.Status, ColorYellow))
+	fmt.Fprintf(&app.frame, "   %-15s%s %s\n", app.procCPULabel()+":",
+		app.colorize(fmt.Sprintf("%.1f%%", app.procCPU(proc.CPUPercent)), cpuColor),
+		app.colorize(internal.Sparkline(app.watchedHistory.Values(), sparklineWidth*2), cpuColor))
+	fmt.Fprintf(&app.frame, "   Memory:        %s (%.1f%%)\n",
+		app.colorize(app.formatMB(proc.MemoryMB), app.getUsageColor(float64(proc.MemPercent))),
+		proc.MemPercent)
+	fmt.Fprintf(&app.frame, "   Threads:       %s\n", app.colorize(fmt.Sprintf("%d", proc.NumThreads), ColorYellow))
+	fmt.Fprintf(&app.frame, "   Nice:          %s\n", app.colorize(fmt.Sprintf("%d", proc.Nice), niceColor(proc.Nice)))
+	fmt.Fprintf(&app.frame, "   Open FDs:      %s\n", app.colorize(app.formatFDs(proc.NumFDs), app.fdColor(*proc)))
+	fmt.Fprintf(&app.frame, "   Uptime:        %s\n", app.colorize(app.formatProcessUptime(proc.CreateTime), ColorGreen))
+	fmt.Fprintf(&app.frame, "   Command:       %s\n", app.colorize(app.truncateString(printable(proc.CommandLine), 62), ColorDim))
+}
+
+// printable replaces control characters, such as newlines in a command
+// line, which would break the screen layout
+func printable(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// toggleCommand opens or closes the full command line of the target process
+func (app *App) toggleCommand() {
+	if app.commandProc != nil {
+		app.commandProc, app.commandDetails, app.commandDetailsErr = nil, nil, nil
+	} else if proc, err := app.targetProcess(); err != nil {
+		app.statusMessage = "Command: " + err.Error()
+	} else {
+		app.commandProc = proc
+		// Read once on opening; these are too costly to collect every scan
+		if app.localStats() {
+			app.commandDetails, app.commandDetailsErr = internal.GetProcessDetails(app.ctx, proc.PID)
+		}
+	}
+	app.displayInterface()
+}
+
+// sensitiveEnvWords mark environment variables whose values are redacted
+// unless -show-secrets is given
+var sensitiveEnvWords = []string{"SECRET", "TOKEN", "PASSWORD", "PASSWD", "API_KEY", "CREDENTIAL"}
+
+// redactEnv hides the value of a KEY=value entry whose name contains one
+// of sensitiveEnvWords
+func redactEnv(entry string) string {
+	name, _, _ := strings.Cut(entry, "=")
+	upper := strings.ToUpper(name)
+	for _, word := range sensitiveEnvWords {
+		if strings.Contains(upper, word) {
+			return name + "=<redacted>"
+		}
+	}
+	return entry
+}
+
+// detailError describes why a process detail could not be read
+func detailError(err error) string {
+	if errors.Is(err, os.ErrPermission) {
+		return "permission denied"
+	}
+	return err.Error()
+}
+
+// displayCommand shows a process's whole command line, wrapped to the
+// screen width, with its executable, working directory and environment
+func (app *App) displayCommand() {
+	proc := app.commandProc
+	fmt.Fprintf(&app.frame, "%s%s Command of PID %d (%s)%s\n", app.colorize("", ColorBold+ColorPurple), app.icon("📄"), proc.PID, proc.Name, app.colorize("", ColorReset))
+	fmt.Fprintln(&app.frame, app.colorize(strings.Repeat("─", 80), ColorDim))
+
+	command := []rune(printable(proc.CommandLine))
+	for len(command) > 78 {
+		fmt.Fprintf(&app.frame, "  %s\n", app.colorize(string(command[:78]), ColorCyan))
+		command = command[78:]
+	}
+	fmt.Fprintf(&app.frame, "  %s\n\n", app.colorize(string(command), ColorCyan))
+
+	app.displayProcessDetails()
+
+	fmt.Fprint(&app.frame, app.colorize("Press W or Esc to return...", ColorDim))
+}
+
+// displayProcessDetails shows the executable, working directory and
+// environment read when the command overlay opened
+func (app *App) displayProcessDetails() {
+	switch {
+	case !app.localStats():
+		fmt.Fprintln(&app.frame, app.colorize("  Executable, directory and environment are only shown for local processes", ColorDim))
+		fmt.Fprintln(&app.frame)
+		return
+	case app.commandDetailsErr != nil:
+		fmt.Fprintf(&app.frame, "  %s\n\n", app.colorize("Details unavailable: "+detailError(app.commandDetailsErr), ColorRed))
+		return
+	}
+
+	details := app.commandDetails
+	field := func(label, value string, err error) {
+		if err != nil {
+			value = app.colorize(detailError(err), ColorDim)
+		} else {
+			value = app.colorize(app.truncateString(printable(value), 60), ColorCyan)
+		}
+		fmt.Fprintf(&app.frame, "  %-12s %s\n", label+":", value)
+	}
+	field("Executable", details.Exe, details.ExeErr)
+	field("Directory", details.Cwd, details.CwdErr)
+
+	if details.EnvironErr != nil {
+		field("Environment", "", details.EnvironErr)
+		fmt.Fprintln(&app.frame)
+		return
+	}
+	fmt.Fprintf(&app.frame, "  Environment: %d variables\n", len(details.Environ))
+	env := slices.Sorted(slices.Values(details.Environ))
+	limit := maxEnvLines
+	if app.compactMode {
+		limit = maxEnvLines / 2
+	}
+	for i, entry := range env {
+		if i == limit {
+			fmt.Fprintln(&app.frame, app.colorize(fmt.Sprintf("    ... and %d more", len(env)-limit), ColorDim))
+			break
+		}
+		if !app.showSecrets {
+			entry = redactEnv(entry)
+		}
+		fmt.Fprintf(&app.frame, "    %s\n", app.truncateString(printable(entry), 76))
+	}
+	fmt.Fprintln(&app.frame)
 }
 
 func (app *App) displayFooter() {
-	fmt.Println()
-	fmt.Print(app.colorize("┌", ColorCyan))
-	fmt.Print(app.colorize(strings.Repeat("─", 78), ColorCyan))
-	fmt.Print(app.colorize("┐", ColorCyan))
-	fmt.Println()
+	fmt.Fprintln(&app.frame)
+	fmt.Fprint(&app.frame, app.colorize("┌", app.theme.Border))
+	fmt.Fprint(&app.frame, app.colorize(strings.Repeat("─", 78), app.theme.Border))
+	fmt.Fprint(&app.frame, app.colorize("┐", app.theme.Border))
+	fmt.Fprintln(&app.frame)
 
 	controls := ""
-	if app.logToFile {
+	if app.logToFile && app.logPaused {
+		controls += app.colorize("[L]og:PAUSED ", ColorYellow)
+	} else if app.logToFile {
 		controls += app.colorize("[L]og:ON ", ColorGreen)
 	} else {
 		controls += app.colorize("[L]og:OFF ", ColorRed)
@@ -522,41 +2357,137 @@ func (app *App) displayFooter() {
 		controls += app.colorize("[C]ompact:OFF ", ColorGreen)
 	}
 
-	fmt.Printf("│ %s%s │\n", controls, strings.Repeat(" ", 78-len(stripColors(controls))))
+	if app.safeMode {
+		controls += app.colorize("read-only mode ", ColorCyan)
+	}
+
+	timing, timed := app.collectTiming()
+	if timed {
+		color := ColorDim
+		if timing.Total() >= app.currentRefreshRate()*overlapWarnPercent/100 {
+			color = ColorYellow
+		}
+		controls += app.colorize(fmt.Sprintf("collect: %dms", timing.Total().Milliseconds()), color)
+	}
+
+	fmt.Fprintf(&app.frame, "│ %s%s │\n", controls, strings.Repeat(" ", 78-len(stripColors(controls))))
+
+	if timed && timing.Total() >= app.currentRefreshRate()*overlapWarnPercent/100 {
+		warning := app.truncateString(fmt.Sprintf("%s Collecting takes %s of the %s refresh; refreshes run back to back",
+			app.icon("⚠"), timing.Total().Round(time.Millisecond), app.currentRefreshRate()), 78)
+		fmt.Fprintf(&app.frame, "│ %s%s │\n", app.colorize(warning, ColorYellow), strings.Repeat(" ", 78-len([]rune(warning))))
+	}
+
+	if app.clockJump != 0 {
+		warning := app.truncateString(fmt.Sprintf("%s Clock jump detected (%s), rates may be inaccurate", app.icon("⚠"), signedDuration(app.clockJump)), 78)
+		fmt.Fprintf(&app.frame, "│ %s%s │\n", app.colorize(warning, ColorYellow), strings.Repeat(" ", 78-len([]rune(warning))))
+	}
+
+	if app.lastAlert != nil {
+		alert := app.truncateString(fmt.Sprintf("%s %s %s", app.icon("⚠"), app.lastAlert.Time.Format("15:04:05"), app.lastAlert.Message), 78)
+		fmt.Fprintf(&app.frame, "│ %s%s │\n", app.colorize(alert, ColorBold+ColorRed), strings.Repeat(" ", 78-len([]rune(alert))))
+	}
+
+	if app.statusMessage != "" {
+		status := app.truncateString(app.statusMessage, 78)
+		fmt.Fprintf(&app.frame, "│ %s%s │\n", app.colorize(status, ColorDim), strings.Repeat(" ", 78-len([]rune(status))))
+	}
+
+	if app.prompt != "" {
+		line := app.truncateString(app.prompt+": "+string(app.promptInput)+"_", 78)
+		fmt.Fprintf(&app.frame, "│ %s%s │\n", app.colorize(line, ColorBold+ColorYellow), strings.Repeat(" ", 78-len([]rune(line))))
+	}
 
 	shortcuts := app.colorize("[H]elp [E]xport [R]efresh [+/-]Speed [Q]uit", ColorDim)
-	fmt.Printf("│ %s%s │\n", shortcuts, strings.Repeat(" ", 78-len(stripColors(shortcuts))))
+	fmt.Fprintf(&app.frame, "│ %s%s │\n", shortcuts, strings.Repeat(" ", 78-len(stripColors(shortcuts))))
 
-	fmt.Print(app.colorize("└", ColorCyan))
-	fmt.Print(app.colorize(strings.Repeat("─", 78), ColorCyan))
-	fmt.Print(app.colorize("┘", ColorCyan))
-	fmt.Println()
+	fmt.Fprint(&app.frame, app.colorize("└", app.theme.Border))
+	fmt.Fprint(&app.frame, app.colorize(strings.Repeat("─", 78), app.theme.Border))
+	fmt.Fprint(&app.frame, app.colorize("┘", app.theme.Border))
+	fmt.Fprintln(&app.frame)
 }
 
 func (app *App) displayHelp() {
-	fmt.Printf("%s📚 System Monitor Help%s\n\n", app.colorize("", ColorBold+ColorYellow), app.colorize("", ColorReset))
-
-	fmt.Printf("%sNavigation:%s\n", app.colorize("", ColorBold+ColorGreen), app.colorize("", ColorReset))
-	fmt.Printf("  %s1-5%s    Switch between views (Overview, Processes, Network, Disks, System)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
-	fmt.Printf("  %sH/?%s    Show/hide this help screen\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
-	fmt.Printf("  %sQ%s      Quit the application\n\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
-
-	fmt.Printf("%sControl:%s\n", app.colorize("", ColorBold+ColorGreen), app.colorize("", ColorReset))
-	fmt.Printf("  %sP%s      Pause/resume updates\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
-	fmt.Printf("  %sR%s      Force refresh\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
-	fmt.Printf("  %sC%s      Toggle compact mode\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
-	fmt.Printf("  %s+/-%s    Increase/decrease refresh rate\n\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "%s%s System Monitor Help%s\n\n", app.colorize("", ColorBold+ColorYellow), app.icon("📚"), app.colorize("", ColorReset))
+
+	fmt.Fprintf(&app.frame, "%sNavigation:%s\n", app.colorize("", ColorBold+ColorGreen), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %s1-5%s    Switch between views (Overview, Processes, Network, Disks, System)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %s6%s      Watched process detail (with -pid)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %s7%s      Graph of CPU, memory and network over -graph-window\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sTab/→%s  Next view (Shift-Tab/←: previous view)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %s↑/↓%s    Select a host with -hosts (Enter: open, Esc: back to the grid)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %s↑/↓%s    Select a filesystem in the Disks view (Enter: processes using it)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sH/?%s    Show/hide this help screen (Esc closes it)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sQ%s      Quit the application\n\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+
+	fmt.Fprintf(&app.frame, "%sControl:%s\n", app.colorize("", ColorBold+ColorGreen), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sp%s      Pause/resume updates of the display\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sShift-P%s Pause/resume writing to the log, keeping the display live\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sR%s      Force refresh (also works while paused)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sC%s      Toggle compact mode\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sN%s      Toggle per-process network column (Linux)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sG%s      Show hostnames of connection remote addresses (reverse DNS)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sF%s      Show/hide pseudo filesystems in disk views\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sD%s      Highlight processes started or exited since the last refresh\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sO%s      Cycle the disk order: none, used%%, free space, size, mountpoint\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sT%s      Toggle temperatures between Celsius and Fahrenheit\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sI%s      Toggle emoji icons and ASCII labels\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sb%s      Jump to the view of the most concerning metric (full disk, busy CPU, saturated link)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sShift-B%s Reset the session traffic totals in the Network view\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sA%s      Group processes by name in the Processes view\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sV%s      Switch the Processes view between lists and a process tree\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sX%s      Collapse/expand the process tree below the first level\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %s/%s      Filter network interfaces by regexp (!regexp hides matches)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sU%s      Show only the processes of some users (comma-separated, empty for all)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sW%s      Full command line, executable, directory and environment of the top CPU or watched process\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %s>/<%s    %s\n", app.colorize("", app.actionKeyColor()), app.colorize("", ColorReset),
+		app.actionHelp("Lower/raise the priority (niceness ±5) of the top CPU or watched process"))
+	fmt.Fprintf(&app.frame, "  %s%%%s      Per-process CPU%% of one core (can pass 100%%) or of all cores\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %s]/[%s    Show 5 more/fewer processes in the top lists\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %s+/-%s    Increase/decrease refresh rate\n\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+
+	fmt.Fprintf(&app.frame, "%sLogging & Export:%s\n", app.colorize("", ColorBold+ColorGreen), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sL%s      Toggle logging to file\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sJ%s      Event log: alerts, exits of top processes and actions such as logging or exports\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sM%s      Mark snapshot A, then B to compare them: CPU, memory, network, disk growth and processes\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sK%s      Peak CPU, memory and network since launch, and when (Z resets them)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sS%s      Summary of the logging session: CPU, memory and swap min/avg/max\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %sE%s      Export current stats to JSON or CSV (both with -export-formats json,csv)\n\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+
+	fmt.Fprintf(&app.frame, "%sColor Legend:%s\n", app.colorize("", ColorBold+ColorGreen), app.colorize("", ColorReset))
+	fmt.Fprintf(&app.frame, "  %s●%s Low usage (≤ %g%%)\n", app.colorize("", app.theme.Low), app.colorize("", ColorReset), app.warnThreshold)
+	fmt.Fprintf(&app.frame, "  %s●%s Medium usage (%g-%g%%)\n", app.colorize("", app.theme.Medium), app.colorize("", ColorReset), app.warnThreshold, app.critThreshold)
+	fmt.Fprintf(&app.frame, "  %s●%s High usage (> %g%%)\n\n", app.colorize("", app.theme.High), app.colorize("", ColorReset), app.critThreshold)
+
+	fmt.Fprintf(&app.frame, "%sPress any key to return...%s", app.colorize("", ColorDim), app.colorize("", ColorReset))
+}
 
-	fmt.Printf("%sLogging & Export:%s\n", app.colorize("", ColorBold+ColorGreen), app.colorize("", ColorReset))
-	fmt.Printf("  %sL%s      Toggle logging to file\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
-	fmt.Printf("  %sE%s      Export current stats to JSON file\n\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
+// refuseInSafeMode reports whether -safe rules out an action that changes
+// the system, saying so in the footer. Handlers of such keys call it first.
+func (app *App) refuseInSafeMode(action string) bool {
+	if !app.safeMode {
+		return false
+	}
+	app.statusMessage = action + " is disabled in read-only mode (-safe)"
+	app.displayInterface()
+	return true
+}
 
-	fmt.Printf("%sColor Legend:%s\n", app.colorize("", ColorBold+ColorGreen), app.colorize("", ColorReset))
-	fmt.Printf("  %s●%s Low usage (< 60%%)\n", app.colorize("", ColorGreen), app.colorize("", ColorReset))
-	fmt.Printf("  %s●%s Medium usage (60-80%%)\n", app.colorize("", ColorYellow), app.colorize("", ColorReset))
-	fmt.Printf("  %s●%s High usage (> 80%%)\n\n", app.colorize("", ColorRed), app.colorize("", ColorReset))
+// actionKeyColor is the help screen color of a key that changes the
+// system, greyed out in read-only mode
+func (app *App) actionKeyColor() string {
+	if app.safeMode {
+		return ColorDim
+	}
+	return ColorYellow
+}
 
-	fmt.Printf("%sPress any key to return...%s", app.colorize("", ColorDim), app.colorize("", ColorReset))
+// actionHelp is the help screen description of such a key
+func (app *App) actionHelp(description string) string {
+	if app.safeMode {
+		return app.colorize(description+" (disabled: read-only mode)", ColorDim)
+	}
+	return description
 }
 
 // Helper functions
@@ -567,33 +2498,124 @@ func (app *App) colorize(text string, color string) string {
 	return color + text + ColorReset
 }
 
+// icon returns the emoji for a section header, or its ASCII label when
+// emojis are disabled. Emojis carrying a variation selector are padded with
+// an extra space since many terminals draw them one cell narrower.
+func (app *App) icon(emoji string) string {
+	if app.noEmoji {
+		return emojiLabels[emoji]
+	}
+	if strings.HasSuffix(emoji, "\ufe0f") {
+		return emoji + " "
+	}
+	return emoji
+}
+
 func (app *App) getUsageColor(percent float64) string {
-	if percent > 80 {
-		return ColorRed
-	} else if percent > 60 {
-		return ColorYellow
+	if percent > app.critThreshold {
+		return app.theme.High
+	} else if percent > app.warnThreshold {
+		return app.theme.Medium
 	}
-	return ColorGreen
+	return app.theme.Low
 }
 
 func (app *App) getProgressBar(percent float64, width int, color string) string {
-	filled := int(percent / 100 * float64(width))
+	return app.renderBar(percent, width, app.barStyle)
+}
+
+// cpuSegment is one part of the stacked CPU time bar
+type cpuSegment struct {
+	name    string
+	percent float64
+	color   string
+	char    string // drawn instead of a colored block without colors
+}
+
+func cpuSegments(cpu internal.CPUInfo) []cpuSegment {
+	return []cpuSegment{
+		{"user", cpu.UserPercent, ColorGreen, "u"},
+		{"system", cpu.SystemPercent, ColorRed, "s"},
+		{"iowait", cpu.IOWaitPercent, ColorYellow, "w"},
+		{"steal", cpu.StealPercent, ColorPurple, "!"},
+	}
+}
+
+// getCPUTimeBar stacks the user, system, iowait and steal shares of CPU
+// time in one bar, with idle time as the empty rest
+func (app *App) getCPUTimeBar(cpu internal.CPUInfo, width int) string {
 	bar := "["
-	for i := 0; i < width; i++ {
-		if i < filled {
-			if percent > 80 {
-				bar += app.colorize("█", ColorRed)
-			} else if percent > 60 {
-				bar += app.colorize("▓", ColorYellow)
-			} else {
-				bar += app.colorize("▒", ColorGreen)
+	drawn, cumulative := 0, 0.0
+	for _, segment := range cpuSegments(cpu) {
+		cumulative += segment.percent
+		// Rounding the running total keeps the segments from drifting
+		end := min(int(cumulative/100*float64(width)+0.5), width)
+		if end > drawn {
+			char := segment.char
+			if app.colorEnabled && app.barStyle != BarASCII {
+				char = "█"
 			}
-		} else {
-			bar += app.colorize("░", ColorDim)
+			bar += app.colorize(strings.Repeat(char, end-drawn), segment.color)
+			drawn = end
 		}
 	}
-	bar += app.colorize("]", ColorReset)
-	return bar
+	empty := "░"
+	if app.barStyle == BarASCII {
+		empty = "-"
+	}
+	bar += app.colorize(strings.Repeat(empty, width-drawn), ColorDim)
+	return bar + "]"
+}
+
+// visibleDisks applies the filesystem type filter unless all filesystems
+// were requested. Collection and exports always keep the full list.
+func (app *App) visibleDisks(disks []internal.DiskInfo) []internal.DiskInfo {
+	if !app.showAllFilesystems {
+		disks = internal.FilterDisks(disks, app.excludedFstypes)
+	}
+	return sortDisks(disks, app.diskSort)
+}
+
+// DiskSortKey selects the order of the disk lists
+type DiskSortKey int
+
+const (
+	DiskSortNone  DiskSortKey = iota // enumeration order
+	DiskSortUsed                     // fullest first
+	DiskSortFree                     // least free space first
+	DiskSortSize                     // largest first
+	DiskSortMount                    // alphabetical by mountpoint
+	diskSortKeys                     // number of sort keys, for cycling
+)
+
+var diskSortNames = map[DiskSortKey]string{
+	DiskSortNone:  "none",
+	DiskSortUsed:  "used%",
+	DiskSortFree:  "free",
+	DiskSortSize:  "size",
+	DiskSortMount: "mountpoint",
+}
+
+// sortDisks returns a sorted copy of disks, leaving the input untouched
+func sortDisks(disks []internal.DiskInfo, key DiskSortKey) []internal.DiskInfo {
+	if key == DiskSortNone {
+		return disks
+	}
+	sorted := append([]internal.DiskInfo(nil), disks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		switch key {
+		case DiskSortUsed:
+			return a.UsedPercent > b.UsedPercent
+		case DiskSortFree:
+			return a.Free < b.Free
+		case DiskSortSize:
+			return a.Total > b.Total
+		default:
+			return a.Mountpoint < b.Mountpoint
+		}
+	})
+	return sorted
 }
 
 func (app *App) truncateString(s string, maxLen int) string {
@@ -610,8 +2632,339 @@ func (app *App) formatMB(mb uint64) string {
 	return fmt.Sprintf("%dMB", mb)
 }
 
+func (app *App) formatProcessUptime(createTimeMs int64) string {
+	uptime := internal.ProcessUptime(createTimeMs)
+	if uptime == 0 {
+		return "-"
+	}
+	return internal.FormatUptime(uint64(uptime.Seconds()))
+}
+
+// formatProcessNetwork shows traffic for processes in their own network
+// namespace and falls back to the connection count for everything else
+func (app *App) formatProcessNetwork(pn internal.ProcessNetwork) string {
+	if pn.Isolated {
+		return fmt.Sprintf("↑%s ↓%s",
+			internal.FormatNetworkSpeed(pn.UploadKBps),
+			internal.FormatNetworkSpeed(pn.DownloadKBps))
+	}
+	if pn.Connections == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d conn", pn.Connections)
+}
+
+// formatFDs formats an open file descriptor count, showing "-" when unknown
+func (app *App) formatFDs(numFDs int32) string {
+	if numFDs <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", numFDs)
+}
+
+// fdColor highlights processes approaching the open file limit
+func (app *App) fdColor(proc internal.ProcessInfo) string {
+	if proc.NearFDLimit() {
+		return ColorBold + ColorRed
+	}
+	return ColorDim
+}
+
+// niceColor dims default-priority processes and highlights the rest
+// procCPU converts a per-process CPU percentage, where 100% is one core,
+// to the displayed scale: unchanged, or after % a share of all cores
+func (app *App) procCPU(percent float64) float64 {
+	if app.cpuOfAllCores {
+		if cores := app.cpuCores(); cores > 0 {
+			return percent / float64(cores)
+		}
+	}
+	return percent
+}
+
+// procCPULabel names the scale of procCPU
+func (app *App) procCPULabel() string {
+	if app.cpuOfAllCores {
+		return "CPU%all"
+	}
+	return "CPU%core"
+}
+
+// cpuCores counts the logical cores of the machine the stats come from
+func (app *App) cpuCores() int {
+	if app.localStats() {
+		return runtime.NumCPU()
+	}
+	stats, err := app.collector.SystemStats(app.ctx)
+	if err != nil {
+		return 0
+	}
+	return stats.CPU.Cores
+}
+
+func zombieColor(zombies int) string {
+	if zombies > 0 {
+		return ColorBold + ColorRed
+	}
+	return ColorDim
+}
+
+func niceColor(nice int32) string {
+	switch {
+	case nice < 0:
+		return ColorYellow
+	case nice > 0:
+		return ColorGreen
+	}
+	return ColorDim
+}
+
+// targetProcess returns the process the renice and command keys act on:
+// the watched process in its own view, otherwise the first row of the top
+// CPU list as last drawn. A fresh scan could put a different process, often
+// sysmon itself, at the top of the list the user has not seen yet.
+func (app *App) targetProcess() (*internal.ProcessInfo, error) {
+	if app.currentView == ViewProcess && app.watched != nil {
+		return app.watched, nil
+	}
+	if app.shownTarget == nil {
+		return nil, errors.New("no busy process is on screen; open the Overview or Processes view")
+	}
+	return app.shownTarget, nil
+}
+
+// signalStrength renders a wireless interface's signal as four bars and
+// its level in dBm
+func (app *App) signalStrength(iface internal.NetworkInterface) string {
+	if iface.SignalDBm == 0 {
+		return app.colorize("unknown", ColorDim)
+	}
+	percent := iface.SignalPercent()
+	bars, empty := []rune("▂▄▆█"), "·"
+	if app.barStyle == BarASCII {
+		bars, empty = []rune("||||"), "."
+	}
+	filled := int(math.Ceil(percent / 25))
+	color := ColorGreen
+	switch {
+	case percent < 40:
+		color = ColorRed
+	case percent < 70:
+		color = ColorYellow
+	}
+	return app.colorize(string(bars[:filled]), color) + app.colorize(strings.Repeat(empty, len(bars)-filled), ColorDim) +
+		fmt.Sprintf(" %d dBm", iface.SignalDBm)
+}
+
+// renice shifts the niceness of the target process by delta and reports the
+// outcome in the footer
+func (app *App) renice(delta int) {
+	if app.refuseInSafeMode("Renice") {
+		return
+	}
+	if !app.localStats() {
+		app.statusMessage = "Renice is not available while replaying"
+		if app.remoteHost != "" {
+			app.statusMessage = "Renice is not available for remote hosts"
+		}
+		app.displayInterface()
+		return
+	}
+
+	proc, err := app.targetProcess()
+	if err != nil {
+		app.statusMessage = "Renice failed: " + err.Error()
+		app.displayInterface()
+		return
+	}
+
+	nice := int(proc.Nice) + delta
+	if nice < internal.MinNice {
+		nice = internal.MinNice
+	} else if nice > internal.MaxNice {
+		nice = internal.MaxNice
+	}
+
+	err = internal.SetNice(proc.PID, nice)
+	switch {
+	case err == nil:
+		app.statusMessage = fmt.Sprintf("Reniced PID %d (%s) from %d to %d", proc.PID, proc.Name, proc.Nice, nice)
+		log.Print(app.statusMessage)
+		app.addEvent("%s", app.statusMessage)
+	case errors.Is(err, os.ErrPermission) && nice < int(proc.Nice):
+		app.statusMessage = fmt.Sprintf("Permission denied: lowering the niceness of PID %d needs root", proc.PID)
+	default:
+		app.statusMessage = fmt.Sprintf("Renice of PID %d failed: %v", proc.PID, err)
+	}
+	app.displayInterface()
+}
+
+// flushFrame draws the rendered frame over the previous one in a single
+// write. Each line is cleared to its end and everything below the frame is
+// erased, so the screen never goes blank between frames.
+func (app *App) flushFrame() {
+	frame := bytes.ReplaceAll(app.frame.Bytes(), []byte("\n"), []byte("\033[K\n"))
+	screen := make([]byte, 0, len(frame)+16)
+	screen = append(screen, "\033[H"...)
+	screen = append(screen, frame...)
+	screen = append(screen, "\033[J"...)
+	if _, err := app.out.Write(screen); err != nil {
+		log.Printf("Error drawing screen: %v", err)
+	}
+}
+
 func (app *App) clearScreen() {
-	fmt.Print("\033[2J\033[H") // Clear screen and move cursor to top
+	fmt.Fprint(app.out, "\033[2J\033[H") // Clear screen and move cursor to top
+}
+
+// sampleWatched refreshes the -pid process detail and its CPU history,
+// stopping for good once the process has exited
+func (app *App) sampleWatched() {
+	if app.watcher == nil || app.watchedExited {
+		return
+	}
+	detail, err := app.watcher.Sample(app.ctx)
+	if errors.Is(err, internal.ErrProcessExited) {
+		app.watchedExited = true
+		return
+	}
+	if err != nil {
+		log.Printf("Error sampling PID %d: %v", app.watcher.PID(), err)
+		return
+	}
+	app.watched = detail
+	app.watchedHistory.Add(detail.CPUPercent)
+}
+
+// refresh takes a new sample from every source and redraws the screen
+func (app *App) refresh() {
+	app.collect(func() { app.sampling(app.refreshSample) })
+}
+
+// refreshSample does the work of a refresh, on one sample of the stats
+func (app *App) refreshSample() {
+	if app.showingHosts() {
+		app.pollHosts()
+		app.displayInterface()
+	} else {
+		app.advance()
+		app.checkClock()
+		app.recordUsage()
+		app.trackProcessExits()
+		app.trackProcessChanges()
+		app.sampleNetSpeeds()
+		app.recordGraph()
+		app.sampleDiskIO()
+		app.sampleDiskUsage()
+		app.sampleWatched()
+		app.checkResources()
+		app.displayInterface()
+		app.adaptRefreshRate()
+		if app.recordFile != nil {
+			app.recordSnapshot()
+		}
+	}
+	if app.influx != nil {
+		app.sendInflux()
+	}
+}
+
+// checkClock notes a step of the wall clock since the previous refresh,
+// which the footer flags for this refresh. Replays are not checked; their
+// timestamps come from the recording.
+func (app *App) checkClock() {
+	if !app.localStats() {
+		return
+	}
+	app.clockJump = app.clock.Check(time.Now())
+	if app.clockJump != 0 {
+		app.addEvent("Clock jumped %s, rates may be inaccurate", signedDuration(app.clockJump))
+	}
+}
+
+// signedDuration formats a clock step with its sign, to the second
+func signedDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + (-d).Round(time.Second).String()
+	}
+	return "+" + d.Round(time.Second).String()
+}
+
+// sampleNetSpeeds measures interface speeds once per refresh, so redraws
+// between refreshes reuse them instead of measuring over a tiny interval,
+// and feeds them to the bandwidth alerts
+func (app *App) sampleNetSpeeds() {
+	if !app.localStats() { // speeds are not recorded
+		return
+	}
+	speeds, err := internal.GetNetworkSpeeds(app.ctx, app.netSmoothing)
+	if err != nil {
+		log.Printf("Error getting network speeds: %v", err)
+		return
+	}
+	app.netSpeeds = speeds
+	app.peaks.observeNetwork(speeds, time.Now())
+	if app.bandwidthAlerter != nil {
+		app.raiseAlerts(app.bandwidthAlerter.Check(speeds, time.Now()))
+	}
+}
+
+// checkResources raises critical alerts for full filesystems and memory
+// pressure. Replays are not checked, since their alerts have long passed.
+func (app *App) checkResources() {
+	if !app.localStats() {
+		return
+	}
+	stats, err := app.collector.SystemStats(app.ctx)
+	if err != nil {
+		return
+	}
+	disks := internal.FilterDisks(stats.Disk, app.excludedFstypes)
+	app.raiseAlerts(app.resourceAlerter.EvaluateAlerts(stats, disks, time.Now()))
+}
+
+// sampleDiskIO measures disk activity once per refresh, for the same
+// reason as sampleNetSpeeds
+func (app *App) sampleDiskIO() {
+	if !app.localStats() { // disk I/O is not recorded
+		return
+	}
+	speeds, err := internal.GetDiskIOSpeeds(app.ctx)
+	if err != nil {
+		log.Printf("Error getting disk I/O: %v", err)
+		return
+	}
+	app.diskIO = speeds
+}
+
+// advance moves the collector to the next sample, pausing once a replay
+// without -replay-loop reaches the end of its recording
+func (app *App) advance() {
+	if err := app.collector.Advance(); err == io.EOF {
+		app.paused = true
+	} else if err != nil {
+		log.Printf("Error advancing collector: %v", err)
+	}
+}
+
+// recordUsage appends the current CPU and memory usage to the sparkline
+// histories
+func (app *App) recordUsage() {
+	cpuPercent, memPercent, err := app.collector.Usage(app.ctx)
+	if err != nil {
+		log.Printf("Error sampling usage: %v", err)
+		return
+	}
+	// The first CPU sample spans only the moments since startup, which are
+	// busy and too short to mean much
+	first := len(app.cpuHistory.Values()) == 0
+	app.cpuHistory.Add(cpuPercent)
+	app.memHistory.Add(memPercent)
+	if !first && !app.replaying { // a replay's peaks happened long ago
+		now := time.Now()
+		app.peaks.CPU.Observe(cpuPercent, now, "")
+		app.peaks.Memory.Observe(memPercent, now, "")
+	}
 }
 
 func (app *App) toggleLogging() {
@@ -621,109 +2974,182 @@ func (app *App) toggleLogging() {
 			app.logFile = nil
 		}
 		app.logToFile = false
+		app.addEvent("Logging stopped")
 	} else {
-		// Create logs directory if it doesn't exist
-		os.MkdirAll("logs", 0755)
-
-		// Create log file with timestamp
-		filename := fmt.Sprintf("logs/sysmon_%s.log", time.Now().Format("20060102_150405"))
-		file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		// Create a timestamped log file in logs/, rotating by size
+		file, err := NewRotatingWriter("logs", "sysmon", app.logMaxSize, app.logMaxFiles, app.logCompress)
 		if err != nil {
 			log.Printf("Error creating log file: %v", err)
 			return
 		}
 		app.logFile = file
 		app.logToFile = true
+		app.logPaused = false
+		app.session = newSessionSummary(time.Now())
+		app.addEvent("Logging started to %s", file.Name())
 	}
 	app.displayInterface()
 }
 
-func (app *App) logStats(stats *internal.SystemStats, procStats *internal.ProcessStats, netStats *internal.NetworkStats) {
-	if app.logFile == nil {
-		return
-	}
-
-	logEntry := map[string]interface{}{
-		"timestamp": time.Now().Format(time.RFC3339),
-		"system":    stats,
-		"processes": procStats,
-		"network":   netStats,
+// toggleLogPause stops or resumes writing log entries without closing the
+// log file or touching the display
+func (app *App) toggleLogPause() {
+	switch {
+	case !app.logToFile:
+		app.statusMessage = "Logging is off; press L to start it"
+	case app.logPaused:
+		app.logPaused = false
+		app.addEvent("Logging resumed")
+	default:
+		app.logPaused = true
+		app.addEvent("Logging paused")
 	}
+	app.displayInterface()
+}
 
-	data, err := json.Marshal(logEntry)
+// collectAndLog gathers fresh stats and appends them to the log file
+func (app *App) collectAndLog() {
+	stats, err := app.collector.SystemStats(app.ctx)
 	if err != nil {
-		log.Printf("Error marshaling log entry: %v", err)
+		log.Printf("Error getting stats for log: %v", err)
 		return
 	}
 
-	_, err = app.logFile.Write(append(data, '\n'))
-	if err != nil {
-		log.Printf("Error writing to log file: %v", err)
-	}
-}
+	procStats, _ := app.collector.ProcessStats(app.ctx)
+	netStats, _ := app.collector.NetworkStats(app.ctx)
 
-func (app *App) exportStats() {
-	// Create exports directory if it doesn't exist
-	os.MkdirAll("exports", 0755)
+	app.logStats(stats, procStats, netStats)
+}
 
-	// Get current stats
-	stats, err := internal.GetSystemStats()
+// sendInflux ships the stats to the InfluxDB UDP output: those of the
+// current refresh, or freshly collected ones while paused
+func (app *App) sendInflux() {
+	stats, err := app.collector.SystemStats(app.ctx)
 	if err != nil {
-		log.Printf("Error getting stats for export: %v", err)
 		return
 	}
+	netStats, _ := app.collector.NetworkStats(app.ctx)
 
-	procStats, _ := internal.GetProcessStats()
-	netStats, _ := internal.GetNetworkStats()
+	if err := app.influx.Send(stats, netStats); err != nil {
+		log.Printf("Error sending InfluxDB metrics: %v", err)
+	}
+}
 
-	exportData := map[string]interface{}{
-		"export_timestamp": time.Now().Format(time.RFC3339),
-		"system":           stats,
-		"processes":        procStats,
-		"network":          netStats,
-		"view":             app.currentView,
-		"refresh_rate":     app.refreshRate.String(),
+// newLogEntry builds the JSON object written for each log or stream sample
+func newLogEntry(stats *internal.SystemStats, procStats *internal.ProcessStats, netStats *internal.NetworkStats) map[string]interface{} {
+	return map[string]interface{}{
+		"schema_version": SchemaVersion,
+		"timestamp":      time.Now().Format(time.RFC3339),
+		"system":         stats,
+		"processes":      procStats,
+		"network":        netStats,
 	}
+}
 
-	// Create filename with timestamp
-	filename := fmt.Sprintf("exports/sysmon_export_%s.json", time.Now().Format("20060102_150405"))
+// markClockJump flags a log or stream entry taken across a step of the wall
+// clock, whose rates and timestamp may be off
+func markClockJump(entry map[string]interface{}, jump time.Duration) {
+	if jump != 0 {
+		entry["clock_jump_seconds"] = jump.Seconds()
+	}
+}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		log.Printf("Error creating export file: %v", err)
+func (app *App) logStats(stats *internal.SystemStats, procStats *internal.ProcessStats, netStats *internal.NetworkStats) {
+	if app.logFile == nil {
 		return
 	}
-	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
+	entry := newLogEntry(stats, procStats, netStats)
+	if app.localStats() {
+		markClockJump(entry, app.logClock.Check(time.Now()))
+	}
+	var data []byte
+	switch app.logFormat {
+	case LogLogfmt:
+		data = []byte(formatLogfmt(entry, app.excludedFstypes))
+	default:
+		var err error
+		if data, err = json.Marshal(entry); err != nil {
+			log.Printf("Error marshaling log entry: %v", err)
+			return
+		}
+	}
 
-	if err := encoder.Encode(exportData); err != nil {
-		log.Printf("Error encoding export data: %v", err)
+	if _, err := app.logFile.Write(append(data, '\n')); err != nil {
+		log.Printf("Error writing to log file: %v", err)
 		return
 	}
+	app.session.Add(stats, app.netSpeeds)
+}
 
-	log.Printf("Stats exported to %s", filename)
+func (app *App) exportStats() {
+	written, err := app.ExportAll(exportTargets(app.exportPath, app.exportFormats))
+	var messages []string
+	if len(written) > 0 {
+		log.Printf("Stats exported to %s", strings.Join(written, ", "))
+		messages = append(messages, "Exported to "+strings.Join(written, ", "))
+		if isExportDir(app.exportPath) { // timestamped files in a directory
+			if err := pruneExports(filepath.Dir(written[0]), app.exportKeep); err != nil {
+				log.Printf("Error pruning old exports: %v", err)
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("Error exporting stats: %v", err)
+		messages = append(messages, "Export failed: "+strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+	app.statusMessage = strings.Join(messages, "; ")
+	app.addEvent("%s", app.statusMessage)
+	app.displayInterface()
 }
 
+// shutdown ends a normal session
+func (app *App) shutdown() {
+	app.cleanup()
+	fmt.Fprintln(app.out, "System Monitor shutdown complete. Goodbye!")
+}
+
+// cleanup saves the UI state, closes output files and restores the
+// terminal. Only the first call does anything, so the quit path and the
+// panic handler can both call it.
 func (app *App) cleanup() {
+	if app.cleanedUp {
+		return
+	}
+	app.cleanedUp = true
+
+	if app.statePath != "" {
+		if err := app.uiState().Save(app.statePath); err != nil {
+			log.Printf("Error saving UI state: %v", err)
+		}
+	}
 	if app.logFile != nil {
 		app.logFile.Close()
 	}
+	if app.recordFile != nil {
+		app.recordFile.Close()
+	}
+	if app.restoreInput != nil {
+		app.restoreInput()
+	}
+	if app.colorEnabled {
+		fmt.Fprint(app.out, ColorReset)
+	}
+	if app.cursorHidden {
+		fmt.Fprint(app.out, "\033[?25h")
+	}
 	app.clearScreen()
-	fmt.Println("System Monitor shutdown complete. Goodbye!")
 }
 
-func handleKeyboardInput(inputChan chan rune) {
-	reader := bufio.NewReader(os.Stdin)
-	for {
-		char, _, err := reader.ReadRune()
-		if err != nil {
-			close(inputChan)
-			return
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
-		inputChan <- char
 	}
+	return items
 }
 
 func stripColors(text string) string {
@@ -5,21 +5,36 @@ package main
 
 import (
 	"flag"
+
+	"github.com/imunderthetree/sysmon/internal"
 )
 
 func main() {
 	// Parse command line flags
 	guiMode := flag.Bool("gui", false, "Run in GUI mode (using Fyne)")
 	tuiMode := flag.Bool("tui", false, "Run in Terminal UI mode")
+	opts := registerFlags()
 	flag.Parse()
+	applyEnv()
+	internal.SetDiskTimeout(opts.DiskTimeout)
+
+	if opts.ShowVersion {
+		printVersion()
+		return
+	}
+
+	// Headless JSON modes never start a UI
+	if runHeadless(opts) {
+		return
+	}
 
 	// Determine which mode to run
 	// Default to GUI mode if no mode specified
 	if *guiMode || (!*tuiMode && !*guiMode) {
-		initGUI()
+		initGUI(opts.Safe)
 		return
 	}
 
 	// Run TUI mode
-	initTUI()
+	initTUI(opts)
 }
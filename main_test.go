@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+func TestParseUserFilter(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"", nil},
+		{"root", []string{"root"}},
+		{"alice,bob", []string{"alice", "bob"}},
+		{" alice , bob ,, postgres,", []string{"alice", "bob", "postgres"}},
+		{`CORP\alice,www-data`, []string{`CORP\alice`, "www-data"}},
+		{",", nil},
+	}
+	for _, tt := range tests {
+		if got := parseUserFilter(tt.spec); !slices.Equal(got, tt.want) {
+			t.Errorf("parseUserFilter(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesUser(t *testing.T) {
+	users := parseUserFilter("alice,Bob,postgres")
+	tests := []struct {
+		username string
+		want     bool
+	}{
+		{"alice", true},
+		{"bob", true}, // case-insensitive
+		{"POSTGRES", true},
+		{`CORP\alice`, true}, // Windows domain user
+		{`CORP\carol`, false},
+		{"alice2", false},
+		{"root", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := matchesUser(users, tt.username); got != tt.want {
+			t.Errorf("matchesUser(%q, %q) = %v, want %v", users, tt.username, got, tt.want)
+		}
+	}
+	if matchesUser(nil, "alice") {
+		t.Error("an empty user list matched")
+	}
+}
+
+func TestProcessStatsFiltersByUsers(t *testing.T) {
+	all := &internal.ProcessStats{TotalProcesses: 6, AllProcesses: []internal.ProcessInfo{
+		{PID: 1, Name: "systemd", Username: "root", CPUPercent: 1, Status: "sleep"},
+		{PID: 2, Name: "firefox", Username: "alice", CPUPercent: 30, Status: "running"},
+		{PID: 3, Name: "postgres", Username: "postgres", CPUPercent: 5, Status: "sleep"},
+		{PID: 4, Name: "vim", Username: "bob", CPUPercent: 2, Status: "sleep"},
+		{PID: 5, Name: "make", Username: "alice", CPUPercent: 12, Status: "running"},
+		{PID: 6, Name: "sshd", Username: "root", CPUPercent: 50, Status: "sleep"},
+	}}
+	app := &App{
+		ctx:        context.Background(),
+		collector:  &replayCollector{snapshots: []snapshot{{Processes: all}}},
+		userFilter: parseUserFilter("alice, bob"),
+	}
+
+	stats, err := app.processStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pids []int32
+	for _, proc := range stats.TopCPU {
+		pids = append(pids, proc.PID)
+	}
+	if want := []int32{2, 5, 4}; !slices.Equal(pids, want) {
+		t.Errorf("top CPU PIDs %v, want %v", pids, want)
+	}
+	if stats.TotalProcesses != 3 || stats.RunningProcs != 2 || stats.SleepingProcs != 1 {
+		t.Errorf("counts: total %d, running %d, sleeping %d; want 3, 2, 1",
+			stats.TotalProcesses, stats.RunningProcs, stats.SleepingProcs)
+	}
+
+	app.userFilter = nil
+	if stats, _ := app.processStats(); stats != all {
+		t.Error("without a filter the collected stats should pass through")
+	}
+}
+
+func TestDisplayHeaderFitsRemoteHostAndUserFilter(t *testing.T) {
+	app := &App{
+		ctx:         context.Background(),
+		refreshRate: 3 * time.Second,
+		remoteHost:  "http://db-primary-replica.eu-west-1.internal:9100",
+		userFilter:  parseUserFilter("postgres,www-data,backup"),
+	}
+	app.displayHeader()
+
+	// Borders and margins around 78 columns of text
+	info := strings.Split(app.frame.String(), "\n")[2]
+	if width := utf8.RuneCountInString(info); width != 82 {
+		t.Errorf("time line is %d columns wide, want 82: %q", width, info)
+	}
+	if !strings.Contains(info, " on http://db-primary") {
+		t.Errorf("time line lost the host: %q", info)
+	}
+	if !strings.HasSuffix(info, " User: postgres,www-da... | Refresh: 3s │") {
+		t.Errorf("host should give way to the user filter: %q", info)
+	}
+}
+
+func TestProcessChangesKeptUntilNextRefresh(t *testing.T) {
+	before := &internal.ProcessStats{AllProcesses: []internal.ProcessInfo{
+		{PID: 1, Name: "systemd", Username: "root", CreateTime: 100},
+		{PID: 2, Name: "postgres", Username: "postgres", CreateTime: 200},
+		{PID: 3, Name: "cron", Username: "root", CreateTime: 300},
+	}}
+	after := &internal.ProcessStats{AllProcesses: []internal.ProcessInfo{
+		{PID: 1, Name: "systemd", Username: "root", CreateTime: 100},
+		{PID: 4, Name: "postgres", Username: "postgres", CreateTime: 400},
+		{PID: 5, Name: "sh", Username: "root", CreateTime: 500},
+	}}
+	app := &App{
+		ctx:         context.Background(),
+		collector:   &replayCollector{snapshots: []snapshot{{Processes: before}, {Processes: after}}},
+		showChanges: true,
+		topN:        10,
+		userFilter:  parseUserFilter("postgres"),
+	}
+	app.trackProcessChanges()
+	app.collector.Advance()
+	app.trackProcessChanges()
+
+	// Redraws in between, such as for key presses, show the same changes
+	for range 2 {
+		app.frame.Reset()
+		app.displayProcessesView()
+		out := app.frame.String()
+		if !strings.Contains(out, "Started/Exited Since Last Refresh: +1 -1") {
+			t.Fatalf("want one start and one exit of the postgres user's processes:\n%s", out)
+		}
+		if !strings.Contains(out, "+ 4 ") || !strings.Contains(out, "- 2 ") {
+			t.Errorf("want PID 4 started and PID 2 exited:\n%s", out)
+		}
+	}
+	if len(app.startedProcs) != 2 || len(app.exitedProcs) != 2 {
+		t.Errorf("tracked %d started and %d exited, want 2 and 2 across all users",
+			len(app.startedProcs), len(app.exitedProcs))
+	}
+}
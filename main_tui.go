@@ -3,6 +3,26 @@
 
 package main
 
+import (
+	"flag"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
 func main() {
-	initTUI()
+	opts := registerFlags()
+	flag.Parse()
+	applyEnv()
+	internal.SetDiskTimeout(opts.DiskTimeout)
+
+	if opts.ShowVersion {
+		printVersion()
+		return
+	}
+
+	if runHeadless(opts) {
+		return
+	}
+
+	initTUI(opts)
 }
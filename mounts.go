@@ -0,0 +1,84 @@
+// mounts.go - Filesystem selection and the processes keeping a mount busy
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// maxMountUsers bounds the process list under the disks table
+const maxMountUsers = 10
+
+// MountUsers is the outcome of looking up the processes using a mount
+type MountUsers struct {
+	Mountpoint string
+	PIDs       []int32
+	Err        error // may come with a partial PIDs list
+}
+
+// selectDisk moves the disks view's selection by step, wrapping around
+func (app *App) selectDisk(step int) {
+	stats, err := app.collector.SystemStats(app.ctx)
+	if err != nil {
+		return
+	}
+	count := len(app.visibleDisks(stats.Disk))
+	if count == 0 {
+		return
+	}
+	app.diskSelected = (app.diskSelected + step + count) % count
+	app.mountUsers = nil
+	app.displayInterface()
+}
+
+// findMountUsers lists the processes using the selected filesystem
+func (app *App) findMountUsers() {
+	stats, err := app.collector.SystemStats(app.ctx)
+	if err != nil {
+		return
+	}
+	disks := app.visibleDisks(stats.Disk)
+	if app.diskSelected >= len(disks) {
+		return
+	}
+	mountpoint := disks[app.diskSelected].Mountpoint
+	pids, err := internal.ProcessesUsingMount(mountpoint)
+	app.mountUsers = &MountUsers{Mountpoint: mountpoint, PIDs: pids, Err: err}
+	app.displayInterface()
+}
+
+func (app *App) displayMountUsers() {
+	users := app.mountUsers
+	fmt.Fprintln(&app.frame)
+	fmt.Fprintf(&app.frame, "%s%s Processes using %s:%s\n", app.colorize("", ColorBold+ColorPurple), app.icon("📄"), users.Mountpoint, app.colorize("", ColorReset))
+
+	if users.Err != nil && !errors.Is(users.Err, internal.ErrMountUsersPartial) {
+		fmt.Fprintf(&app.frame, "   %s\n", app.colorize(users.Err.Error(), ColorRed))
+		return
+	}
+
+	names := make(map[int32]internal.ProcessInfo)
+	if procStats, err := app.collector.ProcessStats(app.ctx); err == nil {
+		for _, proc := range procStats.AllProcesses {
+			names[proc.PID] = proc
+		}
+	}
+	for i, pid := range users.PIDs {
+		if i >= maxMountUsers {
+			fmt.Fprintf(&app.frame, "   %s\n", app.colorize(fmt.Sprintf("... and %d more", len(users.PIDs)-maxMountUsers), ColorDim))
+			break
+		}
+		proc := names[pid]
+		fmt.Fprintf(&app.frame, "   %-7d %-25s %s\n", pid,
+			app.colorize(app.truncateString(proc.Name, 25), ColorCyan),
+			app.colorize(app.truncateString(proc.Username, 12), ColorDim))
+	}
+	if len(users.PIDs) == 0 {
+		fmt.Fprintf(&app.frame, "   %s\n", app.colorize("None found", ColorGreen))
+	}
+	if users.Err != nil {
+		fmt.Fprintf(&app.frame, "   %s\n", app.colorize(users.Err.Error(), ColorYellow))
+	}
+}
@@ -0,0 +1,73 @@
+// nagios.go - Nagios plugin output (-format nagios)
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// Nagios plugin exit codes
+const (
+	nagiosOK       = 0
+	nagiosWarning  = 1
+	nagiosCritical = 2
+	nagiosUnknown  = 3
+)
+
+// checkLevels lists the metrics of a check: CPU and memory usage against
+// the display thresholds, then memory pressure and the filesystems against
+// the levels of the resource alerts. Sections that failed are left out.
+func checkLevels(stats *internal.SystemStats, disks []internal.DiskInfo, warn, crit float64) []Level {
+	var levels []Level
+	if _, failed := stats.Errors["cpu"]; !failed {
+		levels = append(levels, Level{Name: "cpu", Value: stats.CPU.Usage, Warn: warn, Crit: crit})
+	}
+	if _, failed := stats.Errors["memory"]; !failed {
+		levels = append(levels,
+			Level{Name: "mem", Value: stats.Memory.UsedPercent, Warn: warn, Crit: crit},
+			memoryLevel(stats))
+	}
+	if _, failed := stats.Errors["disk"]; !failed {
+		for _, disk := range disks {
+			levels = append(levels, diskLevel(disk))
+		}
+	}
+	return levels
+}
+
+// writeCheck prints one Nagios plugin line, such as
+//
+//	WARNING - disk_root 88.2%|cpu=23.0%;60;80 mem=61.4%;60;80 disk_root=88.2%;85;95
+//
+// and returns the matching plugin exit code
+func writeCheck(ctx context.Context, w io.Writer, opts *Options) int {
+	stats, err := internal.GetSystemStats(ctx)
+	if err != nil {
+		fmt.Fprintf(w, "UNKNOWN - %v\n", err)
+		return nagiosUnknown
+	}
+
+	// Like the resource alerts, pseudo filesystems are never checked
+	disks := internal.FilterDisks(stats.Disk, splitList(opts.ExcludedFstypes))
+
+	status := nagiosOK
+	var problems, perfdata []string
+	for _, level := range checkLevels(stats, disks, opts.WarnThreshold, opts.CritThreshold) {
+		perfdata = append(perfdata, fmt.Sprintf("%s=%.1f%%;%g;%g", level.Name, level.Value, level.Warn, level.Crit))
+		if severity, over := level.Severity(); over {
+			problems = append(problems, fmt.Sprintf("%s %.1f%%", level.Name, level.Value))
+			status = max(status, nagiosWarning+int(severity))
+		}
+	}
+
+	line := [...]string{"OK", "WARNING", "CRITICAL"}[status]
+	if len(problems) > 0 {
+		line += " - " + strings.Join(problems, ", ")
+	}
+	fmt.Fprintf(w, "%s|%s\n", line, strings.Join(perfdata, " "))
+	return status
+}
@@ -0,0 +1,73 @@
+// notify.go - Desktop notifications for critical alerts
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// notifyInterval is the minimum gap between desktop notifications, so an
+// unsettled system does not flood the desktop
+const notifyInterval = time.Minute
+
+// Notifier delivers alerts outside the terminal
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// noopNotifier drops every alert; it is the default, so headless servers
+// never try to reach a desktop
+type noopNotifier struct{}
+
+func (noopNotifier) Notify(Alert) error { return nil }
+
+// desktopNotifier shows alerts through the platform's notification command
+type desktopNotifier struct {
+	command  func(title, body string) *exec.Cmd
+	interval time.Duration
+	last     time.Time
+}
+
+// NewDesktopNotifier returns a notifier using notify-send on Linux or
+// osascript on macOS, sending at most one notification per interval
+func NewDesktopNotifier(interval time.Duration) (Notifier, error) {
+	var command func(title, body string) *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		command = func(title, body string) *exec.Cmd {
+			return exec.Command("notify-send", "--urgency=critical", "--app-name=sysmon", title, body)
+		}
+	case "darwin":
+		command = func(title, body string) *exec.Cmd {
+			script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(body), strconv.Quote(title))
+			return exec.Command("osascript", "-e", script)
+		}
+	default:
+		return nil, fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+
+	if _, err := exec.LookPath(command("", "").Path); err != nil {
+		return nil, err
+	}
+	return &desktopNotifier{command: command, interval: interval}, nil
+}
+
+// Notify starts the notification command without waiting for it. Alerts
+// within the interval of the last notification are dropped; they still
+// reach the footer and the alert log.
+func (n *desktopNotifier) Notify(alert Alert) error {
+	if !n.last.IsZero() && alert.Time.Sub(n.last) < n.interval {
+		return nil
+	}
+	n.last = alert.Time
+
+	cmd := n.command("sysmon: "+alert.Severity.String(), alert.Message)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
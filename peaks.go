@@ -0,0 +1,76 @@
+// peaks.go - Highest values seen since launch
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// Peak is the highest value of a metric and when it was seen
+type Peak struct {
+	Value  float64
+	At     time.Time // zero until the first sample
+	Detail string    // e.g. the interface of a network peak
+}
+
+// Observe records value if it is a new high
+func (p *Peak) Observe(value float64, at time.Time, detail string) {
+	if p.At.IsZero() || value > p.Value {
+		p.Value, p.At, p.Detail = value, at, detail
+	}
+}
+
+// Peaks are kept every refresh whatever the view, so a spike that has
+// scrolled out of the sparklines is still on record
+type Peaks struct {
+	Since   time.Time
+	CPU     Peak
+	Memory  Peak
+	Network Peak // busiest interface, upload plus download in KB/s
+}
+
+func newPeaks(since time.Time) *Peaks {
+	return &Peaks{Since: since}
+}
+
+// observeNetwork records the busiest interface of one speed sample
+func (p *Peaks) observeNetwork(speeds []internal.NetworkSpeed, at time.Time) {
+	for _, speed := range speeds {
+		p.Network.Observe(speed.UploadKBps+speed.DownloadKBps, at, speed.Interface)
+	}
+}
+
+func (app *App) displayPeaks() {
+	fmt.Fprintf(&app.frame, "%s%s Peaks%s\n", app.colorize("", ColorBold+ColorYellow), app.icon("📈"), app.colorize("", ColorReset))
+	fmt.Fprintln(&app.frame, app.colorize(strings.Repeat("─", 80), ColorDim))
+	fmt.Fprintf(&app.frame, "  Since %s\n\n", app.colorize(app.peaks.Since.Format("2006-01-02 15:04:05"), ColorCyan))
+
+	for _, row := range []struct {
+		name  string
+		peak  Peak
+		value string
+		color string
+	}{
+		{"CPU", app.peaks.CPU, fmt.Sprintf("%.1f%%", app.peaks.CPU.Value), app.getUsageColor(app.peaks.CPU.Value)},
+		{"Memory", app.peaks.Memory, fmt.Sprintf("%.1f%%", app.peaks.Memory.Value), app.getUsageColor(app.peaks.Memory.Value)},
+		{"Network", app.peaks.Network, internal.FormatNetworkSpeed(app.peaks.Network.Value), ColorYellow},
+	} {
+		if row.peak.At.IsZero() {
+			fmt.Fprintf(&app.frame, "  %-9s %s\n", row.name, app.colorize("not measured yet", ColorDim))
+			continue
+		}
+		detail := ""
+		if row.peak.Detail != "" {
+			detail = " on " + row.peak.Detail
+		}
+		fmt.Fprintf(&app.frame, "  %-9s %s at %s%s\n", row.name,
+			app.colorize(fmt.Sprintf("%-12s", row.value), ColorBold+row.color),
+			app.colorize(row.peak.At.Format("15:04:05"), ColorCyan), detail)
+	}
+
+	fmt.Fprintln(&app.frame)
+	fmt.Fprint(&app.frame, app.colorize("Z resets the peaks. Press K or Esc to return...", ColorDim))
+}
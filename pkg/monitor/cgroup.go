@@ -0,0 +1,60 @@
+// pkg/monitor/cgroup.go
+
+package monitor
+
+import "time"
+
+// cgroupLimits are the limits and usage of sysmon's own cgroup
+type cgroupLimits struct {
+	memLimit uint64        // bytes, 0 without a limit
+	memUsage uint64        // bytes, excluding reclaimable page cache
+	cpuQuota float64       // CPUs, 0 without a quota
+	cpuUsage time.Duration // cumulative CPU time
+}
+
+// Previous cgroup CPU time, to turn the cumulative counter into a rate
+var (
+	previousCgroupCPU  time.Duration
+	lastCgroupCPURead  time.Time
+	lastCgroupCPUUsage float64
+)
+
+// applyCgroupLimits fills in the Cgroup fields of cpu and mem when sysmon
+// runs under a cgroup memory limit or CPU quota. A memory limit at or above
+// physical memory (cgroup v1 reports "unlimited" as a huge number) is no
+// limit.
+func applyCgroupLimits(cpu *CPUInfo, mem *MemoryInfo) {
+	limits := readCgroupLimits()
+
+	if limits.memLimit > 0 && (mem.Total == 0 || limits.memLimit < mem.Total) {
+		mem.CgroupMemLimit = limits.memLimit
+		mem.CgroupMemUsed = limits.memUsage
+		mem.CgroupUsedPercent = 100 * float64(limits.memUsage) / float64(limits.memLimit)
+	}
+
+	if limits.cpuQuota > 0 {
+		cpu.CgroupCPUQuota = limits.cpuQuota
+		cpu.CgroupUsage = cgroupCPUPercent(limits.cpuUsage, limits.cpuQuota)
+	}
+}
+
+// cgroupCPUPercent returns the cgroup's CPU time since the previous call
+// as a percentage of its quota. Like cpuUsage the first call returns zero
+// and calls closer than MinSampleInterval reuse the last result.
+func cgroupCPUPercent(usage time.Duration, quota float64) float64 {
+	now := time.Now()
+	if !lastCgroupCPURead.IsZero() && now.Sub(lastCgroupCPURead) < MinSampleInterval {
+		return lastCgroupCPUUsage
+	}
+	previous, elapsed := previousCgroupCPU, now.Sub(lastCgroupCPURead)
+	previousCgroupCPU, lastCgroupCPURead = usage, now
+
+	lastCgroupCPUUsage = 0
+	if previous > 0 && usage >= previous && elapsed > 0 {
+		lastCgroupCPUUsage = 100 * float64(usage-previous) / float64(elapsed) / quota
+		if lastCgroupCPUUsage > 100 {
+			lastCgroupCPUUsage = 100
+		}
+	}
+	return lastCgroupCPUUsage
+}
@@ -0,0 +1,139 @@
+//go:build linux
+// +build linux
+
+// pkg/monitor/cgroup_linux.go
+
+package monitor
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cgroupRoot is where cgroupfs is mounted
+const cgroupRoot = "/sys/fs/cgroup"
+
+// readCgroupLimits returns the memory and CPU limits of sysmon's own
+// cgroup (v2, or v1 memory and cpu controllers) with its current usage.
+// Limits that are not set are left zero.
+func readCgroupLimits() cgroupLimits {
+	var limits cgroupLimits
+	paths := selfCgroupPaths()
+
+	if dir, ok := cgroupDir(paths[""], "memory.max"); ok {
+		// cgroup v2
+		limits.memLimit = readCgroupUint(filepath.Join(dir, "memory.max"))
+		limits.memUsage = readCgroupUint(filepath.Join(dir, "memory.current"))
+		limits.memUsage = subtractInactiveFile(limits.memUsage, filepath.Join(dir, "memory.stat"), "inactive_file")
+		if fields := strings.Fields(readCgroupFile(filepath.Join(dir, "cpu.max"))); len(fields) == 2 && fields[0] != "max" {
+			limits.cpuQuota = cpuQuota(fields[0], fields[1])
+		}
+		limits.cpuUsage = time.Duration(readCgroupStat(filepath.Join(dir, "cpu.stat"), "usage_usec")) * time.Microsecond
+		return limits
+	}
+
+	// cgroup v1
+	if dir, ok := cgroupDir(filepath.Join("memory", paths["memory"]), "memory.limit_in_bytes"); ok {
+		limits.memLimit = readCgroupUint(filepath.Join(dir, "memory.limit_in_bytes"))
+		limits.memUsage = readCgroupUint(filepath.Join(dir, "memory.usage_in_bytes"))
+		limits.memUsage = subtractInactiveFile(limits.memUsage, filepath.Join(dir, "memory.stat"), "total_inactive_file")
+	}
+	if dir, ok := cgroupDir(filepath.Join("cpu", paths["cpu"]), "cpu.cfs_quota_us"); ok {
+		limits.cpuQuota = cpuQuota(readCgroupFile(filepath.Join(dir, "cpu.cfs_quota_us")), readCgroupFile(filepath.Join(dir, "cpu.cfs_period_us")))
+	}
+	if dir, ok := cgroupDir(filepath.Join("cpuacct", paths["cpuacct"]), "cpuacct.usage"); ok {
+		limits.cpuUsage = time.Duration(readCgroupUint(filepath.Join(dir, "cpuacct.usage")))
+	}
+	return limits
+}
+
+// selfCgroupPaths maps controllers ("" for cgroup v2) to sysmon's cgroup
+// path from /proc/self/cgroup
+func selfCgroupPaths() map[string]string {
+	paths := make(map[string]string)
+	file, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return paths
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, controller := range strings.Split(parts[1], ",") {
+			paths[controller] = parts[2]
+		}
+	}
+	return paths
+}
+
+// cgroupDir finds the directory of a cgroup below cgroupRoot that holds
+// file. Inside a container the path from /proc/self/cgroup may not be
+// visible, in which case the mount's top directory is the container's own.
+func cgroupDir(path, file string) (string, bool) {
+	for dir := filepath.Join(cgroupRoot, path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+			return dir, true
+		}
+		if dir == cgroupRoot || !strings.HasPrefix(dir, cgroupRoot) {
+			return "", false
+		}
+	}
+}
+
+func readCgroupFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readCgroupUint reads a numeric cgroup file; "max" and unreadable files
+// give zero
+func readCgroupUint(path string) uint64 {
+	value, _ := strconv.ParseUint(readCgroupFile(path), 10, 64)
+	return value
+}
+
+// readCgroupStat returns a "key value" entry of a cgroup stat file
+func readCgroupStat(path, key string) uint64 {
+	for _, line := range strings.Split(readCgroupFile(path), "\n") {
+		if name, value, ok := strings.Cut(line, " "); ok && name == key {
+			n, _ := strconv.ParseUint(value, 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// subtractInactiveFile leaves reclaimable page cache out of the memory
+// usage, as docker stats does
+func subtractInactiveFile(usage uint64, statPath, key string) uint64 {
+	if inactive := readCgroupStat(statPath, key); inactive < usage {
+		return usage - inactive
+	}
+	return usage
+}
+
+// cpuQuota converts a quota and period in microseconds to CPUs; a missing
+// or negative (unlimited) quota gives zero
+func cpuQuota(quota, period string) float64 {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0
+	}
+	return q / p
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+// pkg/monitor/cgroup_other.go
+
+package monitor
+
+// readCgroupLimits reports no limits; cgroups only exist on Linux
+func readCgroupLimits() cgroupLimits {
+	return cgroupLimits{}
+}
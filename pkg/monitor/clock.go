@@ -0,0 +1,49 @@
+// pkg/monitor/clock.go
+
+package monitor
+
+import "time"
+
+// ClockJumpThreshold is how far the wall clock may drift from the monotonic
+// clock between two samples before ClockWatch reports a jump
+const ClockJumpThreshold = 2 * time.Second
+
+// ClockWatch detects steps of the wall clock between successive samples,
+// such as an NTP correction, a manual change or a VM resumed from suspend.
+// The rates in this package are measured on the monotonic clock that
+// time.Now carries, which such steps leave alone, but timestamps taken
+// alongside them jump, and so do rates computed by anything comparing
+// wall-clock times.
+type ClockWatch struct {
+	last time.Time
+}
+
+// Check records now, which must come from time.Now, and returns how far the
+// wall clock moved beyond the monotonic time elapsed since the previous
+// call: positive when it jumped ahead, negative when it stepped back. It
+// returns zero on the first call and for drift under ClockJumpThreshold.
+func (c *ClockWatch) Check(now time.Time) time.Duration {
+	previous := c.last
+	c.last = now
+	if previous.IsZero() {
+		return 0
+	}
+	return clockJump(previous, now)
+}
+
+// clockJump compares the wall-clock and monotonic intervals between two
+// readings. Readings without a monotonic part, such as decoded timestamps,
+// compare by wall clock alone and never show a jump.
+func clockJump(previous, now time.Time) time.Duration {
+	return wallDrift(now.Round(0).Sub(previous.Round(0)), now.Sub(previous))
+}
+
+// wallDrift returns how far a wall-clock interval strays from the
+// monotonic one over the same readings, or zero under ClockJumpThreshold
+func wallDrift(wall, monotonic time.Duration) time.Duration {
+	jump := wall - monotonic
+	if jump.Abs() < ClockJumpThreshold {
+		return 0
+	}
+	return jump
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWallDrift(t *testing.T) {
+	tests := []struct {
+		name            string
+		wall, monotonic time.Duration
+		want            time.Duration
+	}{
+		{"steady", 5 * time.Second, 5 * time.Second, 0},
+		{"small drift", 5*time.Second + 300*time.Millisecond, 5 * time.Second, 0},
+		{"just under the threshold", 5*time.Second - ClockJumpThreshold + time.Nanosecond, 5 * time.Second, 0},
+		{"at the threshold", 5*time.Second + ClockJumpThreshold, 5 * time.Second, ClockJumpThreshold},
+		{"jumped ahead", time.Hour, 5 * time.Second, time.Hour - 5*time.Second},
+		// NTP or a manual change sets the clock back while monotonic time
+		// keeps moving forward
+		{"stepped back", -time.Hour, 5 * time.Second, -time.Hour - 5*time.Second},
+		{"stepped back behind the previous sample", -3 * time.Second, 2 * time.Second, -5 * time.Second},
+		{"stepped back less than elapsed", 1 * time.Second, 4 * time.Second, -3 * time.Second},
+		{"resumed from suspend", 8 * time.Hour, 5 * time.Second, 8*time.Hour - 5*time.Second},
+	}
+	for _, tt := range tests {
+		if got := wallDrift(tt.wall, tt.monotonic); got != tt.want {
+			t.Errorf("%s: wallDrift(%v, %v) = %v, want %v", tt.name, tt.wall, tt.monotonic, got, tt.want)
+		}
+	}
+}
+
+func TestClockJumpReadings(t *testing.T) {
+	previous := time.Now()
+	now := previous.Add(5 * time.Second) // moves both clocks alike
+	if jump := clockJump(previous, now); jump != 0 {
+		t.Errorf("clockJump with both clocks in step = %v, want 0", jump)
+	}
+
+	// Decoded timestamps have no monotonic reading and compare by wall
+	// clock alone, even when it went backwards
+	decoded := previous.Round(0)
+	if jump := clockJump(decoded, decoded.Add(-time.Hour)); jump != 0 {
+		t.Errorf("clockJump without monotonic readings = %v, want 0", jump)
+	}
+}
+
+func TestClockWatch(t *testing.T) {
+	var watch ClockWatch
+	start := time.Now()
+	if jump := watch.Check(start); jump != 0 {
+		t.Errorf("first Check = %v, want 0", jump)
+	}
+	for i := 1; i <= 3; i++ {
+		if jump := watch.Check(start.Add(time.Duration(i) * time.Second)); jump != 0 {
+			t.Errorf("Check %d = %v, want 0", i, jump)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+//go:build linux
+// +build linux
+
+// pkg/monitor/cpufreq_linux.go
+
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// cpuFrequency returns the average current and the highest maximum core
+// frequency in MHz from cpufreq in sysfs. Without cpufreq the current
+// frequency falls back to the "cpu MHz" line of /proc/cpuinfo, which
+// gopsutil reports in Mhz; zero means unknown.
+func cpuFrequency(infos []cpu.InfoStat) (current, max float64) {
+	paths, _ := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*/cpufreq")
+
+	var sum float64
+	var count int
+	for _, dir := range paths {
+		if khz, ok := readKHz(filepath.Join(dir, "scaling_cur_freq")); ok {
+			sum += khz / 1000
+			count++
+		}
+		if khz, ok := readKHz(filepath.Join(dir, "cpuinfo_max_freq")); ok && khz/1000 > max {
+			max = khz / 1000
+		}
+	}
+
+	if count > 0 {
+		current = sum / float64(count)
+	} else if len(infos) > 0 {
+		current = infos[0].Mhz
+	}
+	return current, max
+}
+
+// readKHz reads a sysfs frequency file, which holds a value in kHz
+func readKHz(path string) (float64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	khz, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil || khz <= 0 {
+		return 0, false
+	}
+	return khz, true
+}
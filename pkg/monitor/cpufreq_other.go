@@ -0,0 +1,18 @@
+//go:build !linux
+// +build !linux
+
+// pkg/monitor/cpufreq_other.go
+
+package monitor
+
+import "github.com/shirou/gopsutil/v3/cpu"
+
+// cpuFrequency returns the maximum core frequency in MHz as reported by
+// gopsutil, which outside Linux is the rated rather than the current clock.
+// The current frequency is not readable here and is reported as zero.
+func cpuFrequency(infos []cpu.InfoStat) (current, max float64) {
+	if len(infos) > 0 {
+		max = infos[0].Mhz
+	}
+	return 0, max
+}
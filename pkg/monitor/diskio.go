@@ -0,0 +1,116 @@
+// pkg/monitor/diskio.go
+
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// DiskIOSpeed is the activity of one block device between two calls to
+// GetDiskIOSpeeds
+type DiskIOSpeed struct {
+	Device    string  `json:"device"`
+	ReadKBps  float64 `json:"read_kbps"`
+	WriteKBps float64 `json:"write_kbps"`
+	// UtilPercent is the share of the interval the device was busy, and
+	// QueueDepth the average number of requests in flight. Both come from
+	// the kernel's busy and weighted I/O times and are zero outside Linux.
+	UtilPercent float64   `json:"util_percent"`
+	QueueDepth  float64   `json:"queue_depth"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Previous disk counters, to turn the cumulative totals into rates
+var (
+	previousDiskIO map[string]disk.IOCountersStat
+	lastDiskIORead time.Time
+	lastDiskIO     []DiskIOSpeed // returned again within MinSampleInterval
+)
+
+// GetDiskIOSpeeds returns per-device throughput, utilization and queue
+// depth since the previous call, busiest first. Like GetNetworkSpeeds the
+// first call only records a baseline and returns nothing. Idle devices,
+// partitions and loop/RAM devices are left out.
+func GetDiskIOSpeeds(ctx context.Context) ([]DiskIOSpeed, error) {
+	counters, err := disk.IOCountersWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	previous, elapsed := previousDiskIO, now.Sub(lastDiskIORead)
+	if previous != nil && elapsed < MinSampleInterval {
+		return lastDiskIO, nil
+	}
+	previousDiskIO, lastDiskIORead = counters, now
+	if previous == nil {
+		return nil, nil
+	}
+
+	seconds := elapsed.Seconds()
+	elapsedMs := float64(elapsed.Milliseconds())
+
+	var speeds []DiskIOSpeed
+	for name, current := range counters {
+		last, ok := previous[name]
+		if !ok || !isWholeDisk(name) {
+			continue
+		}
+
+		speed := DiskIOSpeed{
+			Device:    name,
+			ReadKBps:  float64(counterDelta(current.ReadBytes, last.ReadBytes)) / seconds / 1024,
+			WriteKBps: float64(counterDelta(current.WriteBytes, last.WriteBytes)) / seconds / 1024,
+			Timestamp: now,
+		}
+		if elapsedMs > 0 {
+			speed.UtilPercent = float64(counterDelta(current.IoTime, last.IoTime)) / elapsedMs * 100
+			if speed.UtilPercent > 100 {
+				speed.UtilPercent = 100
+			}
+			speed.QueueDepth = float64(counterDelta(current.WeightedIO, last.WeightedIO)) / elapsedMs
+		}
+
+		if speed.ReadKBps > 0.1 || speed.WriteKBps > 0.1 || speed.UtilPercent > 0.1 {
+			speeds = append(speeds, speed)
+		}
+	}
+
+	sort.Slice(speeds, func(i, j int) bool {
+		if speeds[i].UtilPercent != speeds[j].UtilPercent {
+			return speeds[i].UtilPercent > speeds[j].UtilPercent
+		}
+		return speeds[i].ReadKBps+speeds[i].WriteKBps > speeds[j].ReadKBps+speeds[j].WriteKBps
+	})
+	return speeds, nil
+}
+
+// counterDelta is current-previous, or zero if the counter went backwards
+// (device reset or counter wrap)
+func counterDelta(current, previous uint64) uint64 {
+	if current < previous {
+		return 0
+	}
+	return current - previous
+}
+
+// isWholeDisk skips loop and RAM devices and, on Linux, partitions, whose
+// activity is already counted in their parent disk
+func isWholeDisk(name string) bool {
+	if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+		return false
+	}
+	if runtime.GOOS == "linux" {
+		_, err := os.Stat(filepath.Join("/sys/block", name))
+		return err == nil
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+// pkg/monitor/doc.go
+
+// Package monitor collects system, process and network statistics. It is
+// the supported API for embedding sysmon's collectors in other programs;
+// the terminal and desktop interfaces are built on top of it. Import it as
+//
+//	import "github.com/imunderthetree/sysmon/pkg/monitor"
+//
+// The collectors take a context so callers can bound or cancel collection:
+//
+//	stats, err := monitor.GetSystemStats(ctx)      // CPU, memory, disks, host
+//	procs, err := monitor.GetProcessStats(ctx)     // all processes plus top lists
+//	network, err := monitor.GetNetworkStats(ctx)   // interfaces and connections
+//	speeds, err := monitor.GetNetworkSpeeds(ctx, 0) // rates since the previous call
+//
+// All result types carry JSON tags and are stable across releases. The
+// Format* helpers render byte counts, rates, temperatures and uptimes the
+// way sysmon displays them.
+//
+// GetNetworkSpeeds, GetDiskIOSpeeds and the per-process disk I/O rates in
+// GetProcessStats keep their previous sample in package state, so they
+// measure the interval between successive calls from the whole program.
+// The interface filter set with SetInterfaceFilter and the top list length
+// set with SetTopProcesses are package state too.
+//
+// Because of that state the collectors are not safe for concurrent use:
+// GetSystemStats (through its CPU usage sample), GetProcessStats,
+// GetNetworkSpeeds, GetDiskIOSpeeds and the Set* functions must not run at
+// the same time as one another. A program collecting from several
+// goroutines has to serialize those calls, for example behind one mutex
+// as sysmon's -serve agent does. The Format* and Parse* helpers and the
+// methods of the result types hold no state and may be called from
+// anywhere.
+package monitor
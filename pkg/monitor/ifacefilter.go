@@ -0,0 +1,68 @@
+// pkg/monitor/ifacefilter.go
+
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultInterfaceFilter hides the per-container virtual interfaces and
+// Docker bridges that crowd the interface lists on container hosts
+const DefaultInterfaceFilter = "!^(veth|docker)"
+
+// InterfaceFilter selects network interfaces by name. A nil filter lets
+// every interface through.
+type InterfaceFilter struct {
+	spec    string
+	pattern *regexp.Regexp
+	exclude bool
+}
+
+// ParseInterfaceFilter compiles a filter spec: a regular expression of the
+// interfaces to show, or "!" followed by one of the interfaces to hide.
+// An empty spec returns a nil filter.
+func ParseInterfaceFilter(spec string) (*InterfaceFilter, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	filter := &InterfaceFilter{spec: spec}
+	expr := spec
+	if strings.HasPrefix(expr, "!") {
+		filter.exclude = true
+		expr = expr[1:]
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interface filter %q: %w", spec, err)
+	}
+	filter.pattern = pattern
+	return filter, nil
+}
+
+// Match reports whether the interface passes the filter
+func (f *InterfaceFilter) Match(name string) bool {
+	if f == nil {
+		return true
+	}
+	return f.pattern.MatchString(name) != f.exclude
+}
+
+// String returns the spec the filter was parsed from
+func (f *InterfaceFilter) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.spec
+}
+
+// interfaceFilter applies to GetTopNetworkInterfaces and GetNetworkSpeeds
+var interfaceFilter *InterfaceFilter
+
+// SetInterfaceFilter restricts the interfaces reported by
+// GetTopNetworkInterfaces and GetNetworkSpeeds; nil reports all of them.
+// Loopback interfaces are left out of the top interfaces either way.
+func SetInterfaceFilter(filter *InterfaceFilter) {
+	interfaceFilter = filter
+}
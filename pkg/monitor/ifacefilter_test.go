@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"slices"
+	"testing"
+)
+
+// A typical container host: physical NICs alongside bridges, veth pairs,
+// tunnels and loopback
+var testInterfaceNames = []string{
+	"lo", "eth0", "eth1", "enp3s0", "wlp2s0", "wlan0",
+	"docker0", "br-4f1c2a", "veth1a2b3c", "virbr0", "tun0", "wg0",
+}
+
+func TestParseInterfaceFilter(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"", testInterfaceNames},
+		{"^(eth|en|wl)", []string{"eth0", "eth1", "enp3s0", "wlp2s0", "wlan0"}},
+		{"!^(veth|docker|br-|virbr)", []string{"lo", "eth0", "eth1", "enp3s0", "wlp2s0", "wlan0", "tun0", "wg0"}},
+		{"eth", []string{"eth0", "eth1", "veth1a2b3c"}}, // unanchored, so veth pairs match too
+		{"^veth", []string{"veth1a2b3c"}},
+		{"!eth", []string{"lo", "enp3s0", "wlp2s0", "wlan0", "docker0", "br-4f1c2a", "virbr0", "tun0", "wg0"}},
+		{"^(tun|wg)[0-9]+$", []string{"tun0", "wg0"}},
+		{"^eth0$", []string{"eth0"}},
+		{"nothing-matches", nil},
+		{"!", nil}, // hides everything: the empty pattern matches any name
+	}
+	for _, tt := range tests {
+		filter, err := ParseInterfaceFilter(tt.spec)
+		if err != nil {
+			t.Errorf("ParseInterfaceFilter(%q): %v", tt.spec, err)
+			continue
+		}
+		if filter.String() != tt.spec {
+			t.Errorf("ParseInterfaceFilter(%q).String() = %q", tt.spec, filter.String())
+		}
+		var got []string
+		for _, name := range testInterfaceNames {
+			if filter.Match(name) {
+				got = append(got, name)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("filter %q matched %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseInterfaceFilterInvalid(t *testing.T) {
+	for _, spec := range []string{"(eth", "![", "wl*+"} {
+		if filter, err := ParseInterfaceFilter(spec); err == nil {
+			t.Errorf("ParseInterfaceFilter(%q) = %v, want an error", spec, filter)
+		}
+	}
+}
+
+func TestTopInterfacesHonourFilter(t *testing.T) {
+	filter, err := ParseInterfaceFilter("!^(veth|docker)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetInterfaceFilter(filter)
+	defer SetInterfaceFilter(nil)
+
+	var interfaces []NetworkInterface
+	for i, name := range []string{"lo", "eth0", "docker0", "veth9", "wlan0"} {
+		interfaces = append(interfaces, NetworkInterface{Name: name, BytesRecv: uint64(100 * (i + 1)), HasTraffic: true})
+	}
+	var got []string
+	for _, iface := range GetTopNetworkInterfaces(interfaces, 10) {
+		got = append(got, iface.Name)
+	}
+	// Loopback is left out regardless of the filter
+	if want := []string{"wlan0", "eth0"}; !slices.Equal(got, want) {
+		t.Errorf("top interfaces %v, want %v", got, want)
+	}
+}
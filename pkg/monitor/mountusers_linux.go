@@ -0,0 +1,132 @@
+//go:build linux
+// +build linux
+
+// pkg/monitor/mountusers_linux.go
+
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProcessesUsingMount returns the PIDs with an open file, working directory
+// or root directory on the filesystem mounted at mountpoint, the way
+// "lsof +f -- mountpoint" would. Other users' processes can only be read as
+// root; when some were skipped, the PIDs found are returned together with
+// ErrMountUsersPartial.
+func ProcessesUsingMount(mountpoint string) ([]int32, error) {
+	mountpoint = filepath.Clean(mountpoint)
+	mounts, err := readMountpoints()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	// Paths are matched by name rather than stat'ed, which could hang on
+	// the very mount being investigated
+	onMount := func(path string) bool {
+		return strings.HasPrefix(path, "/") && mountOf(path, mounts) == mountpoint
+	}
+
+	var pids []int32
+	skipped := false
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue // not a process
+		}
+		dir := filepath.Join("/proc", entry.Name())
+
+		using, readable := false, true
+		for _, link := range []string{"cwd", "root"} {
+			target, err := os.Readlink(filepath.Join(dir, link))
+			if os.IsPermission(err) {
+				readable = false
+			} else if err == nil && onMount(target) {
+				using = true
+			}
+		}
+
+		fds, err := os.ReadDir(filepath.Join(dir, "fd"))
+		if os.IsPermission(err) {
+			readable = false
+		}
+		for _, fd := range fds {
+			if using {
+				break
+			}
+			target, err := os.Readlink(filepath.Join(dir, "fd", fd.Name()))
+			if err == nil && onMount(target) {
+				using = true
+			}
+		}
+
+		if using {
+			pids = append(pids, int32(pid))
+		} else if !readable {
+			skipped = true
+		}
+	}
+
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+	if skipped {
+		return pids, ErrMountUsersPartial
+	}
+	return pids, nil
+}
+
+// readMountpoints lists the mountpoints of this mount namespace
+func readMountpoints() ([]string, error) {
+	data, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return nil, err
+	}
+	var mounts []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			mounts = append(mounts, unescapeMountpoint(fields[1]))
+		}
+	}
+	return mounts, nil
+}
+
+// unescapeMountpoint decodes the octal escapes (\040 for a space) that
+// /proc/self/mounts uses in paths
+func unescapeMountpoint(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+// mountOf returns the deepest of mounts that contains path. Sockets, pipes
+// and other anonymous files ("socket:[123]") must be filtered out first.
+func mountOf(path string, mounts []string) string {
+	best := ""
+	for _, mount := range mounts {
+		if len(mount) <= len(best) {
+			continue
+		}
+		if mount == "/" || path == mount || strings.HasPrefix(path, mount+"/") {
+			best = mount
+		}
+	}
+	return best
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+// pkg/monitor/mountusers_other.go
+
+package monitor
+
+// ProcessesUsingMount is only implemented on Linux
+func ProcessesUsingMount(mountpoint string) ([]int32, error) {
+	return nil, ErrMountUsersUnsupported
+}
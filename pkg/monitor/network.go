@@ -0,0 +1,586 @@
+// pkg/monitor/network.go
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	stdnet "net"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// NetworkInterface holds information about a network interface
+type NetworkInterface struct {
+	Name        string    `json:"name"`
+	BytesSent   uint64    `json:"bytes_sent"`
+	BytesRecv   uint64    `json:"bytes_recv"`
+	PacketsSent uint64    `json:"packets_sent"`
+	PacketsRecv uint64    `json:"packets_recv"`
+	Errin       uint64    `json:"errin"`
+	Errout      uint64    `json:"errout"`
+	Dropin      uint64    `json:"dropin"`
+	Dropout     uint64    `json:"dropout"`
+	Speed       uint64    `json:"speed"` // Interface speed in Mbps
+	IsUp        bool      `json:"is_up"`
+	HasTraffic  bool      `json:"has_traffic"`
+	Addresses   []string  `json:"addresses"` // CIDR notation, e.g. 192.168.1.10/24
+	MAC         string    `json:"mac"`
+	SessionSent uint64    `json:"session_sent"` // Since the TrafficBaseline, if applied
+	SessionRecv uint64    `json:"session_recv"`
+	IsWireless  bool      `json:"is_wireless"`
+	SignalDBm   int       `json:"signal_dbm,omitempty"` // wireless signal level, 0 when unknown (Linux only)
+	LastUpdate  time.Time `json:"last_update"`
+}
+
+// NetworkStats holds all network statistics
+type NetworkStats struct {
+	Interfaces   []NetworkInterface  `json:"interfaces"`
+	TotalSent    uint64              `json:"total_sent"`
+	TotalRecv    uint64              `json:"total_recv"`
+	SessionSent  uint64              `json:"session_sent"`
+	SessionRecv  uint64              `json:"session_recv"`
+	ActiveIfaces int                 `json:"active_interfaces"`
+	Connections  int                 `json:"connections"`
+	Breakdown    ConnectionBreakdown `json:"connection_breakdown"`
+	Timestamp    time.Time           `json:"timestamp"`
+}
+
+// ConnectionStateCounts holds socket counts per connection state
+type ConnectionStateCounts struct {
+	Listen      int `json:"listen"`
+	Established int `json:"established"`
+	TimeWait    int `json:"time_wait"`
+	CloseWait   int `json:"close_wait"`
+	Syn         int `json:"syn"` // SYN_SENT and SYN_RECV
+	Other       int `json:"other"`
+}
+
+// ConnectionBreakdown splits inet connections by protocol, and TCP ones by
+// state. UDP sockets have no state, so they are only counted.
+type ConnectionBreakdown struct {
+	TCP ConnectionStateCounts `json:"tcp"`
+	UDP int                   `json:"udp"`
+}
+
+// NetworkSpeed holds speed calculations. The Smoothed fields carry the
+// exponential moving average and equal the raw values when smoothing is off.
+type NetworkSpeed struct {
+	Interface            string    `json:"interface"`
+	UploadKBps           float64   `json:"upload_kbps"`
+	DownloadKBps         float64   `json:"download_kbps"`
+	SmoothedUploadKBps   float64   `json:"smoothed_upload_kbps"`
+	SmoothedDownloadKBps float64   `json:"smoothed_download_kbps"`
+	Timestamp            time.Time `json:"timestamp"`
+}
+
+// ListenPort describes a socket in the LISTEN state and its owning process
+type ListenPort struct {
+	Protocol    string `json:"protocol"`
+	Address     string `json:"address"`
+	Port        uint32 `json:"port"`
+	PID         int32  `json:"pid"`
+	ProcessName string `json:"process_name"`
+}
+
+// Connection describes an established inet socket and its owning process
+type Connection struct {
+	Protocol    string `json:"protocol"`
+	LocalPort   uint32 `json:"local_port"`
+	RemoteAddr  string `json:"remote_addr"`
+	RemotePort  uint32 `json:"remote_port"`
+	PID         int32  `json:"pid"`
+	ProcessName string `json:"process_name"`
+}
+
+// ProcessNetwork holds best-effort network activity attributed to a process
+type ProcessNetwork struct {
+	PID          int32   `json:"pid"`
+	Connections  int     `json:"connections"`
+	Isolated     bool    `json:"isolated"` // Process has its own network namespace
+	UploadKBps   float64 `json:"upload_kbps"`
+	DownloadKBps float64 `json:"download_kbps"`
+}
+
+// ErrProcessNetworkUnsupported is returned by GetProcessNetwork on platforms
+// without per-process network accounting
+var ErrProcessNetworkUnsupported = errors.New("per-process network stats are not supported on this platform")
+
+// Global variables to track previous readings for speed calculation
+var (
+	previousNetStats  map[string]NetworkInterface
+	lastNetworkRead   time.Time
+	smoothedNetSpeeds map[string]NetworkSpeed
+	lastNetSpeeds     []NetworkSpeed // returned again within MinSampleInterval
+)
+
+// GetNetworkStats collects network interface statistics. If ctx is cancelled
+// after the interface counters were read, the connection count is left at
+// zero rather than failing the whole call.
+func GetNetworkStats(ctx context.Context) (*NetworkStats, error) {
+	stats := &NetworkStats{
+		Timestamp: time.Now(),
+	}
+
+	// Get network IO counters per interface
+	ioCounters, err := net.IOCountersWithContext(ctx, true) // true = per interface
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network IO counters: %w", err)
+	}
+
+	// Addresses and hardware details, matched to the counters by name
+	details := make(map[string]net.InterfaceStat)
+	if ifaceStats, err := net.InterfacesWithContext(ctx); err == nil {
+		for _, ifaceStat := range ifaceStats {
+			details[ifaceStat.Name] = ifaceStat
+		}
+	}
+
+	signals := wirelessSignals()
+
+	var interfaces []NetworkInterface
+	var totalSent, totalRecv uint64
+	var activeCount int
+
+	// Process each interface
+	for _, counter := range ioCounters {
+		var detail *net.InterfaceStat
+		if d, ok := details[counter.Name]; ok {
+			detail = &d
+		}
+		iface := newNetworkInterface(counter, detail)
+
+		if signal, ok := signals[counter.Name]; ok {
+			iface.IsWireless, iface.SignalDBm = true, signal
+		}
+
+		// Skip loopback and inactive interfaces for totals
+		if !isLoopbackInterface(counter.Name) && iface.HasTraffic {
+			totalSent += counter.BytesSent
+			totalRecv += counter.BytesRecv
+			activeCount++
+		}
+
+		interfaces = append(interfaces, iface)
+	}
+
+	// Sort interfaces by total traffic (most active first)
+	sort.Slice(interfaces, func(i, j int) bool {
+		totalI := interfaces[i].BytesSent + interfaces[i].BytesRecv
+		totalJ := interfaces[j].BytesSent + interfaces[j].BytesRecv
+		return totalI > totalJ
+	})
+
+	stats.Interfaces = interfaces
+	stats.TotalSent = totalSent
+	stats.TotalRecv = totalRecv
+	stats.ActiveIfaces = activeCount
+
+	// Get connection counts
+	breakdown, err := getConnectionBreakdown(ctx)
+	if err == nil {
+		stats.Breakdown = breakdown
+		stats.Connections = breakdown.TCP.Established
+	}
+
+	return stats, nil
+}
+
+// GetNetworkSpeeds calculates current network speeds. An alpha in (0, 1)
+// enables exponential moving average smoothing of the Smoothed fields, with
+// higher values reacting faster; any other alpha returns raw deltas only.
+func GetNetworkSpeeds(ctx context.Context, alpha float64) ([]NetworkSpeed, error) {
+	currentStats, err := GetNetworkStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var speeds []NetworkSpeed
+	now := time.Now()
+
+	// Initialize previous stats if first run
+	if previousNetStats == nil {
+		previousNetStats = make(map[string]NetworkInterface)
+		lastNetworkRead = now
+
+		// Store current stats for next calculation
+		for _, iface := range currentStats.Interfaces {
+			previousNetStats[iface.Name] = iface
+		}
+
+		return speeds, nil // Return empty speeds for first run
+	}
+
+	// Calculate time difference
+	if now.Sub(lastNetworkRead) < MinSampleInterval {
+		return lastNetSpeeds, nil
+	}
+	timeDiff := now.Sub(lastNetworkRead).Seconds()
+
+	smoothing := alpha > 0 && alpha < 1
+	smoothed := make(map[string]NetworkSpeed)
+
+	// Calculate speeds for each interface
+	for _, current := range currentStats.Interfaces {
+		if !interfaceFilter.Match(current.Name) {
+			continue
+		}
+		if previous, exists := previousNetStats[current.Name]; exists {
+			// Calculate bytes per second
+			sentDiff := float64(current.BytesSent - previous.BytesSent)
+			recvDiff := float64(current.BytesRecv - previous.BytesRecv)
+
+			speed := NetworkSpeed{
+				Interface:    current.Name,
+				UploadKBps:   (sentDiff / timeDiff) / 1024, // Convert to KB/s
+				DownloadKBps: (recvDiff / timeDiff) / 1024, // Convert to KB/s
+				Timestamp:    now,
+			}
+			speed.SmoothedUploadKBps = speed.UploadKBps
+			speed.SmoothedDownloadKBps = speed.DownloadKBps
+
+			if smoothing {
+				if last, ok := smoothedNetSpeeds[current.Name]; ok {
+					speed.SmoothedUploadKBps = alpha*speed.UploadKBps + (1-alpha)*last.SmoothedUploadKBps
+					speed.SmoothedDownloadKBps = alpha*speed.DownloadKBps + (1-alpha)*last.SmoothedDownloadKBps
+				}
+				smoothed[current.Name] = speed
+			}
+
+			// Only include interfaces with significant traffic
+			if speed.UploadKBps > 0.1 || speed.DownloadKBps > 0.1 ||
+				speed.SmoothedUploadKBps > 0.1 || speed.SmoothedDownloadKBps > 0.1 {
+				speeds = append(speeds, speed)
+			}
+		}
+	}
+	smoothedNetSpeeds = smoothed
+
+	// Update previous stats for next calculation
+	for _, iface := range currentStats.Interfaces {
+		previousNetStats[iface.Name] = iface
+	}
+	lastNetworkRead = now
+
+	// Sort by total speed (highest first)
+	sort.Slice(speeds, func(i, j int) bool {
+		totalI := speeds[i].SmoothedUploadKBps + speeds[i].SmoothedDownloadKBps
+		totalJ := speeds[j].SmoothedUploadKBps + speeds[j].SmoothedDownloadKBps
+		return totalI > totalJ
+	})
+
+	lastNetSpeeds = speeds
+	return speeds, nil
+}
+
+// getConnectionBreakdown counts inet connections by protocol and state.
+// Unix domain sockets are ignored.
+func getConnectionBreakdown(ctx context.Context) (ConnectionBreakdown, error) {
+	connections, err := net.ConnectionsWithContext(ctx, "all")
+	if err != nil {
+		return ConnectionBreakdown{}, err
+	}
+	return countConnections(connections), nil
+}
+
+// countConnections tallies connections in a single pass
+func countConnections(connections []net.ConnectionStat) ConnectionBreakdown {
+	var breakdown ConnectionBreakdown
+	for _, conn := range connections {
+		if conn.Family != syscall.AF_INET && conn.Family != syscall.AF_INET6 {
+			continue
+		}
+		if conn.Type == syscall.SOCK_DGRAM {
+			breakdown.UDP++
+			continue
+		}
+
+		counts := &breakdown.TCP
+		switch conn.Status {
+		case "LISTEN":
+			counts.Listen++
+		case "ESTABLISHED":
+			counts.Established++
+		case "TIME_WAIT":
+			counts.TimeWait++
+		case "CLOSE_WAIT":
+			counts.CloseWait++
+		case "SYN_SENT", "SYN_RECV":
+			counts.Syn++
+		default:
+			counts.Other++
+		}
+	}
+	return breakdown
+}
+
+// GetListeningPorts returns all listening inet sockets sorted by port number.
+// Owners that cannot be resolved (typically when not running as root) are
+// reported with a zero PID and a blank process name.
+func GetListeningPorts(ctx context.Context) ([]ListenPort, error) {
+	connections, err := net.ConnectionsWithContext(ctx, "inet")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	names := make(map[int32]string)
+	var ports []ListenPort
+	for _, conn := range connections {
+		if conn.Status != "LISTEN" {
+			continue
+		}
+
+		port := ListenPort{
+			Protocol: connectionProtocol(conn),
+			Address:  conn.Laddr.IP,
+			Port:     conn.Laddr.Port,
+			PID:      conn.Pid,
+		}
+
+		port.ProcessName = processName(ctx, names, conn.Pid)
+		ports = append(ports, port)
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+
+	return ports, nil
+}
+
+// GetEstablishedConnections returns the established inet connections sorted
+// by process name, then remote address. As with GetListeningPorts, owners
+// that cannot be resolved have a zero PID and a blank process name.
+func GetEstablishedConnections(ctx context.Context) ([]Connection, error) {
+	connections, err := net.ConnectionsWithContext(ctx, "inet")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	names := make(map[int32]string)
+	var established []Connection
+	for _, conn := range connections {
+		if conn.Status != "ESTABLISHED" {
+			continue
+		}
+		established = append(established, Connection{
+			Protocol:    connectionProtocol(conn),
+			LocalPort:   conn.Laddr.Port,
+			RemoteAddr:  conn.Raddr.IP,
+			RemotePort:  conn.Raddr.Port,
+			PID:         conn.Pid,
+			ProcessName: processName(ctx, names, conn.Pid),
+		})
+	}
+
+	sort.Slice(established, func(i, j int) bool {
+		a, b := established[i], established[j]
+		if a.ProcessName != b.ProcessName {
+			return a.ProcessName < b.ProcessName
+		}
+		if a.RemoteAddr != b.RemoteAddr {
+			return a.RemoteAddr < b.RemoteAddr
+		}
+		return a.RemotePort < b.RemotePort
+	})
+
+	return established, nil
+}
+
+// processName looks up the name of a socket's owner, caching it in names
+// so processes with many sockets are read once
+func processName(ctx context.Context, names map[int32]string, pid int32) string {
+	if pid <= 0 {
+		return ""
+	}
+	name, cached := names[pid]
+	if !cached {
+		if proc, err := process.NewProcessWithContext(ctx, pid); err == nil {
+			name, _ = proc.NameWithContext(ctx)
+		}
+		names[pid] = name
+	}
+	return name
+}
+
+// connectionProtocol returns "tcp", "tcp6", "udp" or "udp6" for a connection
+func connectionProtocol(conn net.ConnectionStat) string {
+	proto := "tcp"
+	if conn.Type == syscall.SOCK_DGRAM {
+		proto = "udp"
+	}
+	if conn.Family == syscall.AF_INET6 {
+		proto += "6"
+	}
+	return proto
+}
+
+// isLoopbackInterface checks if an interface is a loopback interface
+func isLoopbackInterface(name string) bool {
+	loopbackNames := []string{"lo", "lo0", "Loopback"}
+	for _, loName := range loopbackNames {
+		if name == loName {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTopNetworkInterfaces returns the most active network interfaces that
+// pass the SetInterfaceFilter filter
+func GetTopNetworkInterfaces(interfaces []NetworkInterface, limit int) []NetworkInterface {
+	// Filter out loopback and inactive interfaces
+	var active []NetworkInterface
+	for _, iface := range interfaces {
+		if !isLoopbackInterface(iface.Name) && iface.HasTraffic && interfaceFilter.Match(iface.Name) {
+			active = append(active, iface)
+		}
+	}
+
+	// Sort by total traffic
+	sort.Slice(active, func(i, j int) bool {
+		totalI := active[i].BytesSent + active[i].BytesRecv
+		totalJ := active[j].BytesSent + active[j].BytesRecv
+		return totalI > totalJ
+	})
+
+	if len(active) < limit {
+		return active
+	}
+	return active[:limit]
+}
+
+// newNetworkInterface builds an interface from its counters and its
+// details, which are nil when the platform does not report them
+func newNetworkInterface(counter net.IOCountersStat, detail *net.InterfaceStat) NetworkInterface {
+	iface := NetworkInterface{
+		Name:        counter.Name,
+		BytesSent:   counter.BytesSent,
+		BytesRecv:   counter.BytesRecv,
+		PacketsSent: counter.PacketsSent,
+		PacketsRecv: counter.PacketsRecv,
+		Errin:       counter.Errin,
+		Errout:      counter.Errout,
+		Dropin:      counter.Dropin,
+		Dropout:     counter.Dropout,
+		LastUpdate:  time.Now(),
+	}
+
+	// Check if interface has any traffic (indicates it's active)
+	iface.HasTraffic = (counter.BytesSent > 0 || counter.BytesRecv > 0)
+
+	if detail != nil {
+		iface.MAC = detail.HardwareAddr
+		for _, addr := range detail.Addrs {
+			iface.Addresses = append(iface.Addresses, addr.Addr)
+		}
+		iface.IsUp = hasUpFlag(detail.Flags)
+	} else {
+		// No interface details on this platform; traffic is the best hint
+		iface.IsUp = iface.HasTraffic
+	}
+	return iface
+}
+
+// hasUpFlag reports whether an interface's flags include "up"
+func hasUpFlag(flags []string) bool {
+	for _, flag := range flags {
+		if flag == "up" {
+			return true
+		}
+	}
+	return false
+}
+
+// TrafficBaseline records interface byte counters at a point in time so
+// that later stats can report the traffic since then
+type TrafficBaseline struct {
+	Since    time.Time
+	counters map[string]NetworkInterface
+}
+
+// NewTrafficBaseline captures the counters in stats as the new baseline
+func NewTrafficBaseline(stats *NetworkStats) *TrafficBaseline {
+	b := &TrafficBaseline{
+		Since:    time.Now(),
+		counters: make(map[string]NetworkInterface, len(stats.Interfaces)),
+	}
+	for _, iface := range stats.Interfaces {
+		b.counters[iface.Name] = iface
+	}
+	return b
+}
+
+// Apply sets the Session fields of stats and its interfaces to the traffic
+// since the baseline. Interfaces that appeared later, or whose counters were
+// reset, count all of their current traffic. Like TotalSent/TotalRecv, the
+// session totals leave out loopback interfaces.
+func (b *TrafficBaseline) Apply(stats *NetworkStats) {
+	stats.SessionSent, stats.SessionRecv = 0, 0
+	for i := range stats.Interfaces {
+		iface := &stats.Interfaces[i]
+		iface.SessionSent, iface.SessionRecv = iface.BytesSent, iface.BytesRecv
+		if base, ok := b.counters[iface.Name]; ok {
+			if iface.BytesSent >= base.BytesSent {
+				iface.SessionSent = iface.BytesSent - base.BytesSent
+			}
+			if iface.BytesRecv >= base.BytesRecv {
+				iface.SessionRecv = iface.BytesRecv - base.BytesRecv
+			}
+		}
+
+		if !isLoopbackInterface(iface.Name) {
+			stats.SessionSent += iface.SessionSent
+			stats.SessionRecv += iface.SessionRecv
+		}
+	}
+}
+
+// PrimaryIPv4 returns the first IPv4 address assigned to the interface,
+// without its prefix length, or "" if it has none
+func (iface NetworkInterface) PrimaryIPv4() string {
+	for _, addr := range iface.Addresses {
+		ip, _, _ := strings.Cut(addr, "/")
+		if parsed := stdnet.ParseIP(ip); parsed != nil && parsed.To4() != nil {
+			return ip
+		}
+	}
+	return ""
+}
+
+// SignalPercent maps the wireless signal level onto 0-100, from -90 dBm
+// (barely usable) to -30 dBm (excellent). It is zero when the level is
+// unknown or the interface is wired.
+func (iface NetworkInterface) SignalPercent() float64 {
+	if !iface.IsWireless || iface.SignalDBm == 0 {
+		return 0
+	}
+	return min(max(float64(iface.SignalDBm+90)*100/60, 0), 100)
+}
+
+// FormatNetworkSpeed formats network speed for display
+func FormatNetworkSpeed(kbps float64) string {
+	if kbps >= 1024*1024 {
+		return fmt.Sprintf("%.1f GB/s", kbps/(1024*1024))
+	} else if kbps >= 1024 {
+		return fmt.Sprintf("%.1f MB/s", kbps/1024)
+	} else if kbps >= 1 {
+		return fmt.Sprintf("%.1f KB/s", kbps)
+	} else {
+		return fmt.Sprintf("%.0f B/s", kbps*1024)
+	}
+}
+
+// FormatNetworkBytes formats network byte counts for display
+func FormatNetworkBytes(bytes uint64) string {
+	return FormatBytes(bytes) // Reuse the existing FormatBytes function
+}
@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestNewNetworkInterfaceStatus(t *testing.T) {
+	idle := net.IOCountersStat{Name: "eth0"}
+	busy := net.IOCountersStat{Name: "eth0", BytesSent: 1500, BytesRecv: 9000}
+	tests := []struct {
+		name    string
+		counter net.IOCountersStat
+		detail  *net.InterfaceStat
+		wantUp  bool
+	}{
+		{"up flag, idle", idle, &net.InterfaceStat{Name: "eth0", Flags: []string{"up", "broadcast", "multicast"}}, true},
+		{"up flag, busy", busy, &net.InterfaceStat{Name: "eth0", Flags: []string{"broadcast", "up"}}, true},
+		{"no up flag, busy", busy, &net.InterfaceStat{Name: "eth0", Flags: []string{"broadcast", "multicast"}}, false},
+		{"no flags, idle", idle, &net.InterfaceStat{Name: "eth0"}, false},
+		{"flag names are exact", busy, &net.InterfaceStat{Name: "eth0", Flags: []string{"UP", "lower_up"}}, false},
+		{"no details, busy", busy, nil, true},
+		{"no details, idle", idle, nil, false},
+	}
+	for _, tt := range tests {
+		iface := newNetworkInterface(tt.counter, tt.detail)
+		if iface.IsUp != tt.wantUp {
+			t.Errorf("%s: IsUp = %v, want %v", tt.name, iface.IsUp, tt.wantUp)
+		}
+		if iface.HasTraffic != (tt.counter.BytesSent > 0) {
+			t.Errorf("%s: HasTraffic = %v", tt.name, iface.HasTraffic)
+		}
+	}
+}
+
+func TestNewNetworkInterfaceDetails(t *testing.T) {
+	counter := net.IOCountersStat{Name: "wlan0", BytesSent: 10, BytesRecv: 20, Errin: 1, Dropout: 2}
+	detail := &net.InterfaceStat{
+		Name:         "wlan0",
+		HardwareAddr: "aa:bb:cc:dd:ee:ff",
+		Flags:        []string{"up"},
+		Addrs:        net.InterfaceAddrList{{Addr: "192.168.1.5/24"}, {Addr: "fe80::1/64"}},
+	}
+	iface := newNetworkInterface(counter, detail)
+	if iface.MAC != detail.HardwareAddr || len(iface.Addresses) != 2 || iface.PrimaryIPv4() != "192.168.1.5" {
+		t.Errorf("details not copied: %+v", iface)
+	}
+	if iface.BytesSent != 10 || iface.BytesRecv != 20 || iface.Errin != 1 || iface.Dropout != 2 {
+		t.Errorf("counters not copied: %+v", iface)
+	}
+}
+
+func TestCountConnections(t *testing.T) {
+	tcp := func(status string) net.ConnectionStat {
+		return net.ConnectionStat{Family: syscall.AF_INET, Type: syscall.SOCK_STREAM, Status: status}
+	}
+	connections := []net.ConnectionStat{
+		tcp("LISTEN"), tcp("ESTABLISHED"), tcp("ESTABLISHED"), tcp("TIME_WAIT"),
+		tcp("CLOSE_WAIT"), tcp("SYN_SENT"), tcp("SYN_RECV"), tcp("FIN_WAIT1"),
+		{Family: syscall.AF_INET6, Type: syscall.SOCK_STREAM, Status: "ESTABLISHED"},
+		// UDP sockets are counted whatever status they report
+		{Family: syscall.AF_INET, Type: syscall.SOCK_DGRAM, Status: "NONE"},
+		{Family: syscall.AF_INET6, Type: syscall.SOCK_DGRAM, Status: "ESTABLISHED"},
+		// Unix domain sockets are ignored
+		{Family: syscall.AF_UNIX, Type: syscall.SOCK_STREAM, Status: "ESTABLISHED"},
+		{Family: syscall.AF_UNIX, Type: syscall.SOCK_DGRAM, Status: "NONE"},
+	}
+	want := ConnectionBreakdown{
+		TCP: ConnectionStateCounts{Listen: 1, Established: 3, TimeWait: 1, CloseWait: 1, Syn: 2, Other: 1},
+		UDP: 2,
+	}
+	if got := countConnections(connections); got != want {
+		t.Errorf("countConnections = %+v, want %+v", got, want)
+	}
+}
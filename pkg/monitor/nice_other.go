@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+// pkg/monitor/nice_other.go
+
+package monitor
+
+// SetNice is only implemented on Unix-like systems
+func SetNice(pid int32, nice int) error {
+	return ErrNiceUnsupported
+}
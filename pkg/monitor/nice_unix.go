@@ -0,0 +1,15 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+// pkg/monitor/nice_unix.go
+
+package monitor
+
+import "syscall"
+
+// SetNice changes the niceness of a process, like renice(1). Raising it is
+// always allowed for one's own processes; lowering it needs root (or
+// CAP_SYS_NICE) and fails with a permission error otherwise.
+func SetNice(pid int32, nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, int(pid), nice)
+}
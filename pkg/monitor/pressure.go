@@ -0,0 +1,67 @@
+// pkg/monitor/pressure.go
+
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// Previous swap counters, to detect swapping between samples
+var lastSwapIO uint64
+
+// memoryPressure scores how close the system is to running out of memory
+// on a 0-100 scale. The base score is the share of memory that is not
+// available (page cache counts as available, unlike in UsedPercent); if the
+// system swapped since the previous sample, the remaining headroom is halved.
+func memoryPressure(ctx context.Context, vmem *mem.VirtualMemoryStat) float64 {
+	if vmem.Total == 0 {
+		return 0
+	}
+	pressure := 100 * (1 - float64(vmem.Available)/float64(vmem.Total))
+
+	if swap, err := mem.SwapMemoryWithContext(ctx); err == nil {
+		swapIO := swap.Sin + swap.Sout
+		if lastSwapIO != 0 && swapIO > lastSwapIO {
+			pressure += (100 - pressure) / 2
+		}
+		lastSwapIO = swapIO
+	}
+
+	if pressure < 0 {
+		return 0
+	}
+	return pressure
+}
+
+// readMemoryPSI returns the "some avg10" value from Linux pressure stall
+// information: the percentage of the last 10 seconds in which at least one
+// task waited on memory. It returns -1 where PSI is not available.
+func readMemoryPSI() float64 {
+	file, err := os.Open("/proc/pressure/memory")
+	if err != nil {
+		return -1
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "some" {
+			continue
+		}
+		for _, field := range fields[1:] {
+			if value, ok := strings.CutPrefix(field, "avg10="); ok {
+				if avg, err := strconv.ParseFloat(value, 64); err == nil {
+					return avg
+				}
+			}
+		}
+	}
+	return -1
+}
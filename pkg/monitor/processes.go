@@ -0,0 +1,556 @@
+// pkg/monitor/processes.go
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessInfo holds information about a single process
+type ProcessInfo struct {
+	PID         int32   `json:"pid"`
+	PPID        int32   `json:"ppid"`
+	Name        string  `json:"name"`
+	Username    string  `json:"username"`
+	CPUPercent  float64 `json:"cpu_percent"`
+	MemPercent  float32 `json:"mem_percent"`
+	MemoryMB    uint64  `json:"memory_mb"`
+	Status      string  `json:"status"`
+	CreateTime  int64   `json:"create_time"`
+	NumThreads  int32   `json:"num_threads"`
+	Nice        int32   `json:"nice"`       // -20 (highest priority) to 19
+	NumFDs      int32   `json:"num_fds"`    // 0 when unavailable (permissions, Windows)
+	ReadKBps    float64 `json:"read_kbps"`  // Disk reads since the previous scan
+	WriteKBps   float64 `json:"write_kbps"` // Disk writes since the previous scan
+	CommandLine string  `json:"command_line"`
+}
+
+// ProcessStats holds process statistics and summaries
+type ProcessStats struct {
+	TotalProcesses int           `json:"total_processes"`
+	TotalThreads   int           `json:"total_threads"` // sum of NumThreads, 0 where thread counts are unavailable
+	RunningProcs   int           `json:"running_processes"`
+	SleepingProcs  int           `json:"sleeping_processes"`  // including idle kernel threads
+	DiskWaitProcs  int           `json:"disk_wait_processes"` // uninterruptible sleep, usually I/O
+	StoppedProcs   int           `json:"stopped_processes"`
+	ZombieProcs    int           `json:"zombie_processes"` // exited but not reaped by their parent
+	TopCPU         []ProcessInfo `json:"top_cpu,omitempty"`
+	TopMemory      []ProcessInfo `json:"top_memory,omitempty"`
+	TopIO          []ProcessInfo `json:"top_io,omitempty"`
+	AllProcesses   []ProcessInfo `json:"all_processes,omitempty"`
+	Timestamp      time.Time     `json:"timestamp"`
+	Stale          bool          `json:"stale,omitempty"` // the PID list could not be read; these are the last good stats, from Timestamp
+}
+
+// procIOSample is a process's cumulative disk I/O at one point in time
+type procIOSample struct {
+	createTime int64 // guards against PID reuse between scans
+	readBytes  uint64
+	writeBytes uint64
+	at         time.Time
+	readKBps   float64 // rates measured at this sample, reused within MinSampleInterval
+	writeKBps  float64
+}
+
+// Previous per-process I/O counters for rate calculation, replaced on every
+// scan so PIDs that vanished are dropped
+var previousProcIO map[int32]procIOSample
+
+// procStatic holds the fields of a process that are fixed for its lifetime.
+// Reading them costs several /proc reads per process (the username needs a
+// user lookup too), so full scans reuse them instead of refetching.
+type procStatic struct {
+	createTime int64 // guards against PID reuse between scans
+	name       string
+	username   string
+	cmdline    string
+}
+
+// Static fields from the previous scan, replaced on every scan so PIDs
+// that vanished are evicted
+var previousProcStatic map[int32]procStatic
+
+// Listing PIDs can fail for a moment (a /proc read racing with exits, a
+// permission hiccup), so it is retried, waiting pidRetryDelay and then
+// twice that
+const (
+	pidRetries    = 2
+	pidRetryDelay = 50 * time.Millisecond
+)
+
+// The result of the last complete scan, returned marked Stale when the PID
+// list cannot be read so a momentary failure does not blank the views
+var lastGoodProcessStats *ProcessStats
+
+// GetProcessStats collects information about all running processes. The scan
+// stops early when ctx is cancelled, in which case the partially populated
+// stats are returned together with ctx.Err(). When the PIDs cannot be
+// listed, the stats of the last complete scan are returned with Stale set,
+// or an error if there was none.
+func GetProcessStats(ctx context.Context) (*ProcessStats, error) {
+	stats := &ProcessStats{
+		Timestamp: time.Now(),
+	}
+
+	// Get all process PIDs
+	pids, err := listPids(ctx)
+	if err != nil {
+		if lastGoodProcessStats == nil || ctx.Err() != nil {
+			return nil, err
+		}
+		stale := *lastGoodProcessStats
+		stale.Stale = true
+		return &stale, nil
+	}
+
+	var processes []ProcessInfo
+	var scanErr error
+	currentIO := make(map[int32]procIOSample)
+	currentStatic := make(map[int32]procStatic, len(pids))
+
+	// Collect information for each process
+	for _, pid := range pids {
+		if err := ctx.Err(); err != nil {
+			scanErr = err
+			break
+		}
+
+		proc, err := process.NewProcessWithContext(ctx, pid)
+		if err != nil {
+			continue // Process might have died, skip it
+		}
+
+		procInfo, err := getProcessInfo(ctx, proc, currentStatic)
+		if err != nil {
+			continue // Skip processes we can't access
+		}
+
+		procInfo.ReadKBps, procInfo.WriteKBps = processIORates(ctx, proc, procInfo.CreateTime, currentIO)
+
+		processes = append(processes, procInfo)
+	}
+
+	stats.summarize(processes)
+	if scanErr == nil {
+		previousProcIO = currentIO
+		previousProcStatic = currentStatic
+		lastGoodProcessStats = stats
+	}
+	return stats, scanErr
+}
+
+// listPids lists the running PIDs, retrying with backoff after an error
+func listPids(ctx context.Context) ([]int32, error) {
+	delay := pidRetryDelay
+	for attempt := 0; ; attempt++ {
+		pids, err := process.PidsWithContext(ctx)
+		if err == nil || attempt == pidRetries {
+			return pids, err
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, err
+		}
+		delay *= 2
+	}
+}
+
+// summarize sets AllProcesses to processes and derives the state counts
+// and top lists from them
+func (stats *ProcessStats) summarize(processes []ProcessInfo) {
+	stats.TotalProcesses = len(processes)
+	stats.TotalThreads = 0
+	stats.RunningProcs, stats.SleepingProcs, stats.DiskWaitProcs, stats.StoppedProcs, stats.ZombieProcs = 0, 0, 0, 0, 0
+	stats.AllProcesses = processes
+
+	// Count by status
+	for _, proc := range processes {
+		stats.TotalThreads += int(proc.NumThreads)
+		switch processState(proc.Status) {
+		case "r", "running":
+			stats.RunningProcs++
+		case "s", "sleep", "sleeping", "i", "idle":
+			stats.SleepingProcs++
+		case "d", "blocked":
+			stats.DiskWaitProcs++
+		case "t", "stop", "stopped":
+			stats.StoppedProcs++
+		case "z", "zombie":
+			stats.ZombieProcs++
+		}
+	}
+
+	// Get top processes by CPU
+	stats.TopCPU = getTopProcesses(processes, "cpu", topProcesses)
+
+	// Get top processes by Memory
+	stats.TopMemory = getTopProcesses(processes, "memory", topProcesses)
+
+	// Get top processes by disk I/O
+	stats.TopIO = getTopProcesses(processes, "io", topProcesses)
+}
+
+// FilterProcesses returns a copy of stats holding only the processes keep
+// accepts, with the counts and top lists worked out from those alone. The
+// result is only complete when stats came with AllProcesses.
+func FilterProcesses(stats *ProcessStats, keep func(ProcessInfo) bool) *ProcessStats {
+	filtered := &ProcessStats{Timestamp: stats.Timestamp, Stale: stats.Stale}
+	var processes []ProcessInfo
+	for _, proc := range stats.AllProcesses {
+		if keep(proc) {
+			processes = append(processes, proc)
+		}
+	}
+	filtered.summarize(processes)
+	return filtered
+}
+
+// processState returns the first state of a process status in lower case.
+// gopsutil reports a list of states, which ProcessInfo.Status joins with
+// commas; older recordings hold single letters such as "R".
+func processState(status string) string {
+	state, _, _ := strings.Cut(status, ",")
+	return strings.ToLower(strings.TrimSpace(state))
+}
+
+// getProcessInfo extracts information from a process. When static is not
+// nil the fixed fields are taken from the previous scan where the PID and
+// creation time still match, and recorded in static for the next one.
+func getProcessInfo(ctx context.Context, proc *process.Process, static map[int32]procStatic) (ProcessInfo, error) {
+	var info ProcessInfo
+
+	// Basic info
+	info.PID = proc.Pid
+
+	// Parent PID; not cached, orphans are re-parented
+	if ppid, err := proc.PpidWithContext(ctx); err == nil {
+		info.PPID = ppid
+	}
+
+	// Create time
+	if createTime, err := proc.CreateTimeWithContext(ctx); err == nil {
+		info.CreateTime = createTime
+	}
+
+	// Name, user and command line
+	fixed, ok := previousProcStatic[proc.Pid]
+	if static == nil || !ok || info.CreateTime == 0 || fixed.createTime != info.CreateTime {
+		fixed = getProcStatic(ctx, proc, info.CreateTime)
+	}
+	if static != nil {
+		static[proc.Pid] = fixed
+	}
+	info.Name = fixed.name
+	info.Username = fixed.username
+	info.CommandLine = fixed.cmdline
+
+	// CPU percentage (this might take a moment)
+	if cpuPercent, err := proc.CPUPercentWithContext(ctx); err == nil {
+		info.CPUPercent = cpuPercent
+	}
+
+	// Memory percentage
+	if memPercent, err := proc.MemoryPercentWithContext(ctx); err == nil {
+		info.MemPercent = memPercent
+	}
+
+	// Memory info
+	if memInfo, err := proc.MemoryInfoWithContext(ctx); err == nil {
+		info.MemoryMB = memInfo.RSS / 1024 / 1024 // Convert to MB
+	}
+
+	// Status
+	if status, err := proc.StatusWithContext(ctx); err == nil {
+		info.Status = strings.Join(status, ",")
+	}
+
+	// Number of threads
+	if numThreads, err := proc.NumThreadsWithContext(ctx); err == nil {
+		info.NumThreads = numThreads
+	}
+
+	// Niceness. On Linux gopsutil passes on the raw getpriority value,
+	// which the kernel offsets to 20 - nice so it is never negative.
+	if nice, err := proc.NiceWithContext(ctx); err == nil {
+		if runtime.GOOS == "linux" {
+			nice = 20 - nice
+		}
+		info.Nice = nice
+	}
+
+	// Open file descriptors; permission errors for other users' processes
+	// and platforms without support just leave the count at zero
+	if numFDs, err := proc.NumFDsWithContext(ctx); err == nil {
+		info.NumFDs = numFDs
+	}
+
+	return info, nil
+}
+
+// getProcStatic reads the fields of a process that are fixed for its lifetime
+func getProcStatic(ctx context.Context, proc *process.Process, createTime int64) procStatic {
+	fixed := procStatic{createTime: createTime}
+
+	// Process name
+	if name, err := proc.NameWithContext(ctx); err == nil {
+		fixed.name = name
+	}
+
+	// Username
+	if username, err := proc.UsernameWithContext(ctx); err == nil {
+		fixed.username = username
+	} else {
+		fixed.username = "unknown"
+	}
+
+	// Command line, kept whole for exports; displays truncate it
+	if cmdline, err := proc.CmdlineWithContext(ctx); err == nil && len(cmdline) > 0 {
+		fixed.cmdline = cmdline
+	} else {
+		fixed.cmdline = fixed.name
+	}
+
+	return fixed
+}
+
+// processIORates records the process's I/O counters in current and returns
+// its read and write rates since the previous scan. Processes whose counters
+// are not readable (other users' processes, unsupported platforms) or that
+// are new since the last scan report zero.
+func processIORates(ctx context.Context, proc *process.Process, createTime int64, current map[int32]procIOSample) (readKBps, writeKBps float64) {
+	counters, err := proc.IOCountersWithContext(ctx)
+	if err != nil {
+		return 0, 0
+	}
+
+	sample := procIOSample{
+		createTime: createTime,
+		readBytes:  counters.ReadBytes,
+		writeBytes: counters.WriteBytes,
+		at:         time.Now(),
+	}
+
+	last, ok := previousProcIO[proc.Pid]
+	if !ok || last.createTime != createTime {
+		current[proc.Pid] = sample
+		return 0, 0
+	}
+	if sample.at.Sub(last.at) < MinSampleInterval {
+		// Keep the older sample so the next scan measures a full window
+		current[proc.Pid] = last
+		return last.readKBps, last.writeKBps
+	}
+	elapsed := sample.at.Sub(last.at).Seconds()
+	if sample.readBytes >= last.readBytes {
+		readKBps = float64(sample.readBytes-last.readBytes) / 1024 / elapsed
+	}
+	if sample.writeBytes >= last.writeBytes {
+		writeKBps = float64(sample.writeBytes-last.writeBytes) / 1024 / elapsed
+	}
+	sample.readKBps, sample.writeKBps = readKBps, writeKBps
+	current[proc.Pid] = sample
+	return readKBps, writeKBps
+}
+
+// DefaultTopProcesses is the initial length of the top lists in ProcessStats
+const DefaultTopProcesses = 10
+
+// topProcesses is the length of the top lists, changed with SetTopProcesses
+var topProcesses = DefaultTopProcesses
+
+// SetTopProcesses sets how many processes the TopCPU, TopMemory and TopIO
+// lists of GetProcessStats hold, at least 1
+func SetTopProcesses(n int) {
+	topProcesses = max(n, 1)
+}
+
+// getTopProcesses returns the top N processes sorted by CPU, memory or disk I/O usage
+func getTopProcesses(processes []ProcessInfo, sortBy string, limit int) []ProcessInfo {
+	// Make a copy to avoid modifying the original slice
+	sorted := make([]ProcessInfo, len(processes))
+	copy(sorted, processes)
+
+	// Sort based on the criteria
+	switch sortBy {
+	case "cpu":
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].CPUPercent > sorted[j].CPUPercent
+		})
+	case "memory":
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].MemPercent > sorted[j].MemPercent
+		})
+	case "io":
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].ReadKBps+sorted[i].WriteKBps > sorted[j].ReadKBps+sorted[j].WriteKBps
+		})
+	}
+
+	// Return top N processes
+	if len(sorted) < limit {
+		return sorted
+	}
+	return sorted[:limit]
+}
+
+// ProcessGroup sums the usage of all processes sharing a name
+type ProcessGroup struct {
+	Name       string  `json:"name"`
+	Count      int     `json:"count"`
+	CPUPercent float64 `json:"cpu_percent"`
+	MemPercent float32 `json:"mem_percent"`
+	MemoryMB   uint64  `json:"memory_mb"`
+}
+
+// AggregateByName groups procs by name, summing CPU and memory usage and
+// counting instances. Groups are sorted by total CPU usage, then memory.
+func AggregateByName(procs []ProcessInfo) []ProcessGroup {
+	index := make(map[string]int)
+	var groups []ProcessGroup
+	for _, proc := range procs {
+		i, ok := index[proc.Name]
+		if !ok {
+			i = len(groups)
+			index[proc.Name] = i
+			groups = append(groups, ProcessGroup{Name: proc.Name})
+		}
+		groups[i].Count++
+		groups[i].CPUPercent += proc.CPUPercent
+		groups[i].MemPercent += proc.MemPercent
+		groups[i].MemoryMB += proc.MemoryMB
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].CPUPercent != groups[j].CPUPercent {
+			return groups[i].CPUPercent > groups[j].CPUPercent
+		}
+		return groups[i].MemoryMB > groups[j].MemoryMB
+	})
+	return groups
+}
+
+// ProcessUptime returns how long a process has been running given its
+// creation time in milliseconds since the epoch, as reported by gopsutil.
+// Zero or future creation times yield a zero duration.
+func ProcessUptime(createTimeMs int64) time.Duration {
+	if createTimeMs <= 0 {
+		return 0
+	}
+	uptime := time.Since(time.UnixMilli(createTimeMs))
+	if uptime < 0 {
+		return 0
+	}
+	return uptime
+}
+
+// DefaultFDLimit is the common default soft limit on open files per process
+const DefaultFDLimit = 1024
+
+// NearFDLimit reports whether the process holds more than 80% of
+// DefaultFDLimit open file descriptors, a typical sign of a descriptor leak
+func (p ProcessInfo) NearFDLimit() bool {
+	return p.NumFDs > DefaultFDLimit*8/10
+}
+
+// Range of niceness values accepted by SetNice
+const (
+	MinNice = -20
+	MaxNice = 19
+)
+
+// ErrNiceUnsupported is returned by SetNice on platforms without niceness
+var ErrNiceUnsupported = errors.New("changing process priority is not supported on this platform")
+
+// ErrProcessExited is returned by ProcessWatcher once the watched process is gone
+var ErrProcessExited = errors.New("process exited")
+
+// ProcessDetails holds facts about one process that are too costly to read
+// for every process on every scan. A field that could not be read, usually
+// for lack of permission on other users' processes, is left empty and its
+// error set.
+type ProcessDetails struct {
+	Exe        string   `json:"exe,omitempty"`
+	Cwd        string   `json:"cwd,omitempty"`
+	Environ    []string `json:"environ,omitempty"` // KEY=value entries
+	ExeErr     error    `json:"-"`
+	CwdErr     error    `json:"-"`
+	EnvironErr error    `json:"-"`
+}
+
+// GetProcessDetails reads the executable path, working directory and
+// environment of pid, returning ErrProcessExited if no such process exists
+func GetProcessDetails(ctx context.Context, pid int32) (*ProcessDetails, error) {
+	proc, err := process.NewProcessWithContext(ctx, pid)
+	if errors.Is(err, process.ErrorProcessNotRunning) {
+		return nil, ErrProcessExited
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	details := &ProcessDetails{}
+	details.Exe, details.ExeErr = proc.ExeWithContext(ctx)
+	details.Cwd, details.CwdErr = proc.CwdWithContext(ctx)
+	details.Environ, details.EnvironErr = proc.EnvironWithContext(ctx)
+	return details, nil
+}
+
+// ProcessWatcher samples a single process across refreshes. It keeps the
+// gopsutil handle between samples so CPU usage is measured over the interval
+// since the previous sample rather than the process lifetime.
+type ProcessWatcher struct {
+	proc *process.Process
+}
+
+// NewProcessWatcher starts watching pid, returning ErrProcessExited if no
+// such process exists
+func NewProcessWatcher(ctx context.Context, pid int32) (*ProcessWatcher, error) {
+	proc, err := process.NewProcessWithContext(ctx, pid)
+	if errors.Is(err, process.ErrorProcessNotRunning) {
+		return nil, ErrProcessExited
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w := &ProcessWatcher{proc: proc}
+	// Prime the CPU counters; the first interval sample always reads zero
+	w.proc.PercentWithContext(ctx, 0)
+	return w, nil
+}
+
+// PID returns the watched process ID
+func (w *ProcessWatcher) PID() int32 {
+	return w.proc.Pid
+}
+
+// Sample collects the current state of the watched process. Once the
+// process has exited (or its PID was reused) it returns ErrProcessExited.
+func (w *ProcessWatcher) Sample(ctx context.Context) (*ProcessInfo, error) {
+	running, err := w.proc.IsRunningWithContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil || !running {
+		return nil, ErrProcessExited
+	}
+
+	info, err := getProcessInfo(ctx, w.proc, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cpuPercent, err := w.proc.PercentWithContext(ctx, 0); err == nil {
+		info.CPUPercent = cpuPercent
+	}
+
+	return &info, nil
+}
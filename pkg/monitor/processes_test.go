@@ -0,0 +1,187 @@
+package monitor
+
+import (
+	"context"
+	"math"
+	"os/exec"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+func TestAggregateByName(t *testing.T) {
+	procs := []ProcessInfo{
+		{PID: 10, Name: "chrome", CPUPercent: 12.5, MemPercent: 3, MemoryMB: 300},
+		{PID: 11, Name: "bash", CPUPercent: 0, MemPercent: 0.1, MemoryMB: 5},
+		{PID: 12, Name: "chrome", CPUPercent: 7.5, MemPercent: 2, MemoryMB: 200},
+		{PID: 13, Name: "postgres", CPUPercent: 4, MemPercent: 1, MemoryMB: 120},
+		{PID: 14, Name: "chrome", CPUPercent: 1, MemPercent: 0.5, MemoryMB: 50},
+		{PID: 15, Name: "bash", CPUPercent: 0, MemPercent: 0.1, MemoryMB: 6},
+		{PID: 16, Name: "postgres", CPUPercent: 4, MemPercent: 1.5, MemoryMB: 180},
+	}
+	want := []ProcessGroup{
+		{Name: "chrome", Count: 3, CPUPercent: 21, MemPercent: 5.5, MemoryMB: 550},
+		{Name: "postgres", Count: 2, CPUPercent: 8, MemPercent: 2.5, MemoryMB: 300},
+		{Name: "bash", Count: 2, CPUPercent: 0, MemPercent: 0.2, MemoryMB: 11},
+	}
+
+	got := AggregateByName(procs)
+	if len(got) != len(want) {
+		t.Fatalf("AggregateByName returned %d groups, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Name != w.Name || g.Count != w.Count || g.MemoryMB != w.MemoryMB ||
+			math.Abs(g.CPUPercent-w.CPUPercent) > 1e-9 || math.Abs(float64(g.MemPercent-w.MemPercent)) > 1e-5 {
+			t.Errorf("group %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestAggregateByNameTiesSortByMemory(t *testing.T) {
+	procs := []ProcessInfo{
+		{Name: "small", MemoryMB: 10},
+		{Name: "large", MemoryMB: 40},
+		{Name: "small", MemoryMB: 10},
+	}
+	got := AggregateByName(procs)
+	if len(got) != 2 || got[0].Name != "large" || got[1].Name != "small" || got[1].MemoryMB != 20 {
+		t.Errorf("AggregateByName = %+v, want large (40 MB) before small (20 MB)", got)
+	}
+}
+
+func TestAggregateByNameEmpty(t *testing.T) {
+	if got := AggregateByName(nil); len(got) != 0 {
+		t.Errorf("AggregateByName(nil) = %+v, want no groups", got)
+	}
+}
+
+func TestProcessState(t *testing.T) {
+	tests := []struct{ status, want string }{
+		{process.Running, "running"},
+		{"sleep,daemon", "sleep"}, // gopsutil lists, joined by getProcessInfo
+		{" Zombie ,orphan", "zombie"},
+		{"R", "r"}, // older recordings
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := processState(tt.status); got != tt.want {
+			t.Errorf("processState(%q) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeStateBuckets(t *testing.T) {
+	statuses := []string{
+		// running
+		process.Running, "R", "running,daemon",
+		// sleeping, idle kernel threads included
+		process.Sleep, "S", process.Idle, "I", "sleeping",
+		// disk wait
+		process.Blocked, "D",
+		// stopped
+		process.Stop, "T", "stopped",
+		// zombie
+		process.Zombie, "Z", "zombie,orphan",
+		// counted in the total only
+		process.Wait, process.Lock, process.UnknownState,
+	}
+	var procs []ProcessInfo
+	for i, status := range statuses {
+		procs = append(procs, ProcessInfo{PID: int32(i + 1), Status: status, NumThreads: 2})
+	}
+
+	var stats ProcessStats
+	stats.summarize(procs)
+	want := ProcessStats{
+		TotalProcesses: len(statuses),
+		TotalThreads:   2 * len(statuses),
+		RunningProcs:   3,
+		SleepingProcs:  5,
+		DiskWaitProcs:  2,
+		StoppedProcs:   3,
+		ZombieProcs:    3,
+	}
+	if stats.TotalProcesses != want.TotalProcesses || stats.TotalThreads != want.TotalThreads ||
+		stats.RunningProcs != want.RunningProcs || stats.SleepingProcs != want.SleepingProcs ||
+		stats.DiskWaitProcs != want.DiskWaitProcs || stats.StoppedProcs != want.StoppedProcs ||
+		stats.ZombieProcs != want.ZombieProcs {
+		t.Errorf("counts %+v, want %+v", countsOf(stats), countsOf(want))
+	}
+
+	// A second summary starts from zero rather than adding to the first
+	stats.summarize(procs[:1])
+	if stats.TotalProcesses != 1 || stats.RunningProcs != 1 || stats.SleepingProcs != 0 || stats.ZombieProcs != 0 {
+		t.Errorf("counts after resummarizing %+v", countsOf(stats))
+	}
+}
+
+func TestFilterProcessesRecounts(t *testing.T) {
+	stats := &ProcessStats{Stale: true}
+	stats.summarize([]ProcessInfo{
+		{PID: 1, Username: "root", Status: process.Sleep},
+		{PID: 2, Username: "alice", Status: process.Running},
+		{PID: 3, Username: "alice", Status: process.Zombie},
+		{PID: 4, Username: "bob", Status: process.Blocked},
+	})
+	filtered := FilterProcesses(stats, func(p ProcessInfo) bool { return p.Username == "alice" })
+	if filtered.TotalProcesses != 2 || filtered.RunningProcs != 1 || filtered.ZombieProcs != 1 ||
+		filtered.SleepingProcs != 0 || filtered.DiskWaitProcs != 0 || !filtered.Stale {
+		t.Errorf("filtered counts %+v", countsOf(*filtered))
+	}
+}
+
+// countsOf strips the process lists for readable failure messages
+func countsOf(stats ProcessStats) ProcessStats {
+	stats.TopCPU, stats.TopMemory, stats.TopIO, stats.AllProcesses = nil, nil, nil, nil
+	return stats
+}
+
+// benchProcesses is the process count BenchmarkGetProcessStats scans
+const benchProcesses = 500
+
+// startProcesses tops the system up to n processes with idle children,
+// killed when the benchmark ends
+func startProcesses(b *testing.B, n int) {
+	pids, err := process.Pids()
+	if err != nil {
+		b.Skipf("listing processes: %v", err)
+	}
+	for range n - len(pids) {
+		cmd := exec.Command("sleep", "3600")
+		if err := cmd.Start(); err != nil {
+			b.Skipf("starting processes to scan: %v", err)
+		}
+		b.Cleanup(func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		})
+	}
+}
+
+// Run with -bench GetProcessStats. "cached" is the steady state of the
+// monitor, where the fixed per-process fields come from the previous
+// scan; "cold" reads them all again each time, as the first scan does.
+func BenchmarkGetProcessStats(b *testing.B) {
+	startProcesses(b, benchProcesses)
+	ctx := context.Background()
+
+	b.Run("cached", func(b *testing.B) {
+		if _, err := GetProcessStats(ctx); err != nil {
+			b.Fatal(err)
+		}
+		for b.Loop() {
+			if _, err := GetProcessStats(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cold", func(b *testing.B) {
+		for b.Loop() {
+			previousProcStatic = nil
+			if _, err := GetProcessStats(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
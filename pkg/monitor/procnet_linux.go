@@ -0,0 +1,125 @@
+//go:build linux
+// +build linux
+
+// pkg/monitor/procnet_linux.go
+
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// namespaceCounters holds the summed non-loopback byte counters of a network namespace
+type namespaceCounters struct {
+	sent uint64
+	recv uint64
+}
+
+// Previous namespace readings, keyed by the /proc/<pid>/ns/net link target
+var (
+	previousNamespaceCounters map[string]namespaceCounters
+	lastNamespaceRead         time.Time
+)
+
+// GetProcessNetwork returns best-effort network activity for the given PIDs.
+// Every process gets its inet connection count. Traffic rates are only
+// reported for processes in their own network namespace (e.g. containers),
+// since processes sharing sysmon's namespace also share its counters.
+func GetProcessNetwork(ctx context.Context, pids []int32) (map[int32]ProcessNetwork, error) {
+	connections, err := net.ConnectionsWithContext(ctx, "inet")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	result := make(map[int32]ProcessNetwork, len(pids))
+	for _, pid := range pids {
+		result[pid] = ProcessNetwork{PID: pid}
+	}
+	for _, conn := range connections {
+		if pn, ok := result[conn.Pid]; ok {
+			pn.Connections++
+			result[conn.Pid] = pn
+		}
+	}
+
+	hostNamespace, _ := os.Readlink("/proc/self/ns/net")
+	now := time.Now()
+	elapsed := now.Sub(lastNamespaceRead).Seconds()
+	current := make(map[string]namespaceCounters)
+
+	for pid, pn := range result {
+		namespace, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/net", pid))
+		if err != nil || namespace == hostNamespace {
+			continue // Not permitted, or shares our counters
+		}
+
+		counters, seen := current[namespace]
+		if !seen {
+			counters, err = readNetDev(fmt.Sprintf("/proc/%d/net/dev", pid))
+			if err != nil {
+				continue
+			}
+			current[namespace] = counters
+		}
+
+		pn.Isolated = true
+		if previous, ok := previousNamespaceCounters[namespace]; ok && elapsed > 0 {
+			if counters.sent >= previous.sent {
+				pn.UploadKBps = float64(counters.sent-previous.sent) / elapsed / 1024
+			}
+			if counters.recv >= previous.recv {
+				pn.DownloadKBps = float64(counters.recv-previous.recv) / elapsed / 1024
+			}
+		}
+		result[pid] = pn
+	}
+
+	previousNamespaceCounters = current
+	lastNamespaceRead = now
+
+	return result, nil
+}
+
+// readNetDev sums the byte counters of all non-loopback interfaces in a
+// /proc/net/dev formatted file
+func readNetDev(path string) (namespaceCounters, error) {
+	var counters namespaceCounters
+
+	file, err := os.Open(path)
+	if err != nil {
+		return counters, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 0; scanner.Scan(); line++ {
+		if line < 2 {
+			continue // Two header lines
+		}
+
+		name, data, found := strings.Cut(scanner.Text(), ":")
+		if !found || isLoopbackInterface(strings.TrimSpace(name)) {
+			continue
+		}
+
+		// Receive bytes is the first field, transmit bytes the ninth
+		fields := strings.Fields(data)
+		if len(fields) < 9 {
+			continue
+		}
+		recv, _ := strconv.ParseUint(fields[0], 10, 64)
+		sent, _ := strconv.ParseUint(fields[8], 10, 64)
+		counters.recv += recv
+		counters.sent += sent
+	}
+
+	return counters, scanner.Err()
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+// pkg/monitor/procnet_other.go
+
+package monitor
+
+import "context"
+
+// GetProcessNetwork is only implemented on Linux
+func GetProcessNetwork(ctx context.Context, pids []int32) (map[int32]ProcessNetwork, error) {
+	return nil, ErrProcessNetworkUnsupported
+}
@@ -0,0 +1,575 @@
+// pkg/monitor/stats.go
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// SystemStats holds all system information
+type SystemStats struct {
+	CPU       CPUInfo    `json:"cpu"`
+	Memory    MemoryInfo `json:"memory"`
+	Disk      []DiskInfo `json:"disk"`
+	Host      HostInfo   `json:"host"`
+	Timestamp time.Time  `json:"timestamp"`
+	// Errors maps sections that could not be collected (cpu, memory, disk,
+	// host) to the reason
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+type CPUInfo struct {
+	Usage float64 `json:"usage"`
+	// Where the CPU time went since the previous sample, adding up to 100.
+	// User includes nice; System includes interrupt handling. Steal is
+	// time a hypervisor gave to other guests (VMs only).
+	UserPercent   float64 `json:"user_percent"`
+	SystemPercent float64 `json:"system_percent"`
+	IdlePercent   float64 `json:"idle_percent"`
+	IOWaitPercent float64 `json:"iowait_percent"`
+	StealPercent  float64 `json:"steal_percent"`
+	Cores         int     `json:"cores"`          // logical cores (threads)
+	PhysicalCores int     `json:"physical_cores"` // equals Cores without SMT or when unreadable
+	ModelName     string  `json:"model_name"`
+	// Frequencies are zero when the platform does not expose them
+	FreqCurrentMHz float64 `json:"freq_current_mhz,omitempty"`
+	FreqMaxMHz     float64 `json:"freq_max_mhz,omitempty"`
+	// Cgroup CPU quota in CPUs and the usage as a percentage of it, when
+	// running under a quota, e.g. in a container
+	CgroupCPUQuota float64 `json:"cgroup_cpu_quota,omitempty"`
+	CgroupUsage    float64 `json:"cgroup_usage,omitempty"`
+	// Nil where the platform has no load average, such as Windows
+	Load *LoadAvg `json:"load,omitempty"`
+}
+
+// LoadAvg is the average number of runnable (and on Linux, uninterruptible)
+// tasks over the last 1, 5 and 15 minutes
+type LoadAvg struct {
+	Load1  float64 `json:"load1"`
+	Load5  float64 `json:"load5"`
+	Load15 float64 `json:"load15"`
+}
+
+type MemoryInfo struct {
+	Total           uint64  `json:"total"`
+	Available       uint64  `json:"available"`
+	Used            uint64  `json:"used"`
+	UsedPercent     float64 `json:"used_percent"`
+	Free            uint64  `json:"free"`
+	Buffers         uint64  `json:"buffers"`
+	Cached          uint64  `json:"cached"`
+	SwapTotal       uint64  `json:"swap_total"`
+	SwapUsed        uint64  `json:"swap_used"`
+	SwapUsedPercent float64 `json:"swap_used_percent"`
+	// Linux breakdown, zero (and left out of JSON) elsewhere. Slab is
+	// kernel memory; SReclaimable is the part of it the kernel can free,
+	// like the page cache.
+	Shared       uint64 `json:"shared,omitempty"` // tmpfs and shared memory
+	Slab         uint64 `json:"slab,omitempty"`
+	SReclaimable uint64 `json:"slab_reclaimable,omitempty"`
+	Dirty        uint64 `json:"dirty,omitempty"` // waiting to be written to disk
+	// Pressure is a 0-100 score based on available memory and swapping;
+	// see memoryPressure
+	Pressure float64 `json:"pressure"`
+	// PSI is the Linux memory "some avg10" stall percentage, -1 if unavailable
+	PSI float64 `json:"psi"`
+	// Cgroup memory limit and usage (excluding reclaimable cache) when
+	// running under a limit below physical memory, e.g. in a container
+	CgroupMemLimit    uint64  `json:"cgroup_mem_limit,omitempty"`
+	CgroupMemUsed     uint64  `json:"cgroup_mem_used,omitempty"`
+	CgroupUsedPercent float64 `json:"cgroup_used_percent,omitempty"`
+}
+
+type DiskInfo struct {
+	Device            string  `json:"device"`
+	Mountpoint        string  `json:"mountpoint"`
+	Fstype            string  `json:"fstype"`
+	Total             uint64  `json:"total"`
+	Used              uint64  `json:"used"`
+	Free              uint64  `json:"free"`
+	UsedPercent       float64 `json:"used_percent"`
+	InodesTotal       uint64  `json:"inodes_total"`
+	InodesUsed        uint64  `json:"inodes_used"`
+	InodesFree        uint64  `json:"inodes_free"`
+	InodesUsedPercent float64 `json:"inodes_used_percent"`
+	// Mount options such as "rw" or "noatime". ReadOnly is set for "ro"
+	// mounts, including filesystems the kernel remounted read-only after
+	// errors.
+	Opts     []string `json:"opts,omitempty"`
+	ReadOnly bool     `json:"read_only,omitempty"`
+	// Unresponsive is set when the filesystem did not report its usage
+	// within the disk timeout, e.g. a stale NFS mount. Only the device,
+	// mountpoint and type are filled in then.
+	Unresponsive bool `json:"unresponsive,omitempty"`
+}
+
+type HostInfo struct {
+	Hostname      string `json:"hostname"`
+	OS            string `json:"os"`
+	Platform      string `json:"platform"`
+	KernelVersion string `json:"kernel_version"`
+	Uptime        uint64 `json:"uptime"`
+	BootTime      uint64 `json:"boot_time"` // Unix seconds
+	// Virtualization technology (e.g. "docker", "kvm") and whether this is
+	// the "host" or a "guest"; both empty on bare metal or when unknown
+	VirtSystem string `json:"virt_system,omitempty"`
+	VirtRole   string `json:"virt_role,omitempty"`
+}
+
+// containerSystems are the virtualization systems that share the host
+// kernel, so limits come from cgroups rather than virtual hardware
+var containerSystems = map[string]bool{
+	"docker":        true,
+	"lxc":           true,
+	"openvz":        true,
+	"rkt":           true,
+	"linux-vserver": true,
+}
+
+// InContainer reports whether sysmon runs inside a container as opposed to
+// a virtual machine or bare metal
+func (h HostInfo) InContainer() bool {
+	return h.VirtRole == "guest" && containerSystems[h.VirtSystem]
+}
+
+// GetSystemStats collects all system statistics. Each section (cpu,
+// memory, disk, host) is collected independently: a failing section is
+// recorded in Errors and left zero while the others are still returned.
+// Cancelling ctx aborts the underlying gopsutil calls and returns ctx.Err()
+// alongside whatever was collected.
+func GetSystemStats(ctx context.Context) (*SystemStats, error) {
+	stats := &SystemStats{
+		Timestamp: time.Now(),
+	}
+
+	// Get CPU information
+	if cpuInfo, err := getCPUInfo(ctx); err != nil {
+		stats.addError("cpu", err)
+	} else {
+		stats.CPU = cpuInfo
+	}
+
+	// Get Memory information
+	if memInfo, err := getMemoryInfo(ctx); err != nil {
+		stats.addError("memory", err)
+	} else {
+		stats.Memory = memInfo
+	}
+
+	// Limits of sysmon's cgroup, if any
+	applyCgroupLimits(&stats.CPU, &stats.Memory)
+
+	// Get Disk information
+	if diskInfo, err := getDiskInfo(ctx); err != nil {
+		stats.addError("disk", err)
+	} else {
+		stats.Disk = diskInfo
+	}
+
+	// Get Host information
+	if hostInfo, err := getHostInfo(ctx); err != nil {
+		stats.addError("host", err)
+	} else {
+		stats.Host = hostInfo
+	}
+
+	return stats, ctx.Err()
+}
+
+// addError records the failure of one section of the stats
+func (s *SystemStats) addError(section string, err error) {
+	if s.Errors == nil {
+		s.Errors = make(map[string]string)
+	}
+	s.Errors[section] = err.Error()
+}
+
+// MinSampleInterval is the shortest window the rate-based collectors (CPU
+// usage, network, disk and per-process I/O speeds) measure over. Calls
+// closer together than this (e.g. a redraw followed by recording or an
+// InfluxDB send) reuse the last result instead of a near-empty interval,
+// which would read as zero or as a burst of noise.
+const MinSampleInterval = 500 * time.Millisecond
+
+// cpuSample is the CPU usage and its breakdown over one interval, in percent
+type cpuSample struct {
+	usage, user, system, idle, iowait, steal float64
+}
+
+// Previous cumulative CPU times and result, to average usage between calls
+var (
+	previousCPUTimes *cpu.TimesStat
+	lastCPURead      time.Time
+	lastCPUSample    cpuSample
+)
+
+// cpuUsage returns how CPU time was spent since the previous call. It does
+// not block; the first call only records a baseline and returns zeros, like
+// the first network speeds.
+func cpuUsage(ctx context.Context) (cpuSample, error) {
+	now := time.Now()
+	if previousCPUTimes != nil && now.Sub(lastCPURead) < MinSampleInterval {
+		return lastCPUSample, nil
+	}
+
+	times, err := cpu.TimesWithContext(ctx, false)
+	if err != nil {
+		return cpuSample{}, err
+	}
+	if len(times) == 0 {
+		return cpuSample{}, nil
+	}
+
+	current := times[0]
+	previous := previousCPUTimes
+	previousCPUTimes, lastCPURead = &current, now
+	if previous == nil {
+		return cpuSample{}, nil
+	}
+
+	total := current.Total() - previous.Total()
+	if total <= 0 {
+		return lastCPUSample, nil
+	}
+	share := func(delta float64) float64 {
+		return min(max(100*delta/total, 0), 100)
+	}
+	sample := cpuSample{
+		user:   share(current.User + current.Nice - previous.User - previous.Nice),
+		system: share(current.System + current.Irq + current.Softirq - previous.System - previous.Irq - previous.Softirq),
+		idle:   share(current.Idle - previous.Idle),
+		iowait: share(current.Iowait - previous.Iowait),
+		steal:  share(current.Steal - previous.Steal),
+	}
+	// Idle and I/O wait count as not busy, as in gopsutil's cpu.Percent
+	sample.usage = share(total - (current.Idle + current.Iowait - previous.Idle - previous.Iowait))
+	lastCPUSample = sample
+	return sample, nil
+}
+
+func getCPUInfo(ctx context.Context) (CPUInfo, error) {
+	var cpuInfo CPUInfo
+
+	// Get CPU usage percentage (average since the previous call)
+	sample, err := cpuUsage(ctx)
+	if err != nil {
+		return cpuInfo, err
+	}
+	cpuInfo.Usage = sample.usage
+	cpuInfo.UserPercent, cpuInfo.SystemPercent = sample.user, sample.system
+	cpuInfo.IdlePercent, cpuInfo.IOWaitPercent, cpuInfo.StealPercent = sample.idle, sample.iowait, sample.steal
+
+	// Get CPU count
+	cpuInfo.Cores, err = cpu.CountsWithContext(ctx, true) // logical cores
+	if err != nil {
+		return cpuInfo, err
+	}
+	cpuInfo.PhysicalCores, err = cpu.CountsWithContext(ctx, false)
+	if err != nil || cpuInfo.PhysicalCores <= 0 {
+		cpuInfo.PhysicalCores = cpuInfo.Cores
+	}
+
+	// Get CPU model information
+	cpuInfos, err := cpu.InfoWithContext(ctx)
+	if err != nil {
+		return cpuInfo, err
+	}
+	if len(cpuInfos) > 0 {
+		cpuInfo.ModelName = cpuInfos[0].ModelName
+	}
+	cpuInfo.FreqCurrentMHz, cpuInfo.FreqMaxMHz = cpuFrequency(cpuInfos)
+
+	if avg, err := load.AvgWithContext(ctx); err == nil {
+		cpuInfo.Load = &LoadAvg{Load1: avg.Load1, Load5: avg.Load5, Load15: avg.Load15}
+	}
+
+	return cpuInfo, nil
+}
+
+func getMemoryInfo(ctx context.Context) (MemoryInfo, error) {
+	vmem, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return MemoryInfo{}, err
+	}
+
+	info := MemoryInfo{
+		Total:        vmem.Total,
+		Available:    vmem.Available,
+		Used:         vmem.Used,
+		UsedPercent:  vmem.UsedPercent,
+		Free:         vmem.Free,
+		Buffers:      vmem.Buffers,
+		Cached:       vmem.Cached,
+		Shared:       vmem.Shared,
+		Slab:         vmem.Slab,
+		SReclaimable: vmem.Sreclaimable,
+		Dirty:        vmem.Dirty,
+		Pressure:     memoryPressure(ctx, vmem),
+		PSI:          readMemoryPSI(),
+	}
+	if swap, err := mem.SwapMemoryWithContext(ctx); err == nil {
+		info.SwapTotal, info.SwapUsed, info.SwapUsedPercent = swap.Total, swap.Used, swap.UsedPercent
+	}
+	return info, nil
+}
+
+func getDiskInfo(ctx context.Context) ([]DiskInfo, error) {
+	partitions, err := disk.PartitionsWithContext(ctx, false) // only physical partitions
+	if err != nil {
+		return nil, err
+	}
+
+	var diskInfos []DiskInfo
+	for _, partition := range partitions {
+		if err := ctx.Err(); err != nil {
+			return diskInfos, err
+		}
+
+		usage, err := diskUsage(ctx, partition.Mountpoint)
+		if errors.Is(err, ErrDiskTimeout) {
+			diskInfos = append(diskInfos, DiskInfo{
+				Device:       partition.Device,
+				Mountpoint:   partition.Mountpoint,
+				Fstype:       partition.Fstype,
+				Opts:         partition.Opts,
+				ReadOnly:     slices.Contains(partition.Opts, "ro"),
+				Unresponsive: true,
+			})
+			continue
+		}
+		if err != nil {
+			// Skip partitions we can't access
+			continue
+		}
+
+		diskInfo := DiskInfo{
+			Device:      partition.Device,
+			Mountpoint:  partition.Mountpoint,
+			Fstype:      partition.Fstype,
+			Total:       usage.Total,
+			Used:        usage.Used,
+			Free:        usage.Free,
+			UsedPercent: usage.UsedPercent,
+
+			InodesTotal:       usage.InodesTotal,
+			InodesUsed:        usage.InodesUsed,
+			InodesFree:        usage.InodesFree,
+			InodesUsedPercent: usage.InodesUsedPercent,
+
+			Opts:     partition.Opts,
+			ReadOnly: slices.Contains(partition.Opts, "ro"),
+		}
+		diskInfos = append(diskInfos, diskInfo)
+	}
+
+	return diskInfos, nil
+}
+
+// DefaultDiskTimeout is how long a filesystem gets to report its usage
+const DefaultDiskTimeout = 2 * time.Second
+
+// ErrDiskTimeout means a filesystem did not report its usage in time
+var ErrDiskTimeout = errors.New("filesystem not responding")
+
+var (
+	// ErrMountUsersUnsupported is returned by ProcessesUsingMount outside Linux
+	ErrMountUsersUnsupported = errors.New("finding processes using a mount is only supported on Linux")
+	// ErrMountUsersPartial means ProcessesUsingMount could not inspect
+	// every process
+	ErrMountUsersPartial = errors.New("some processes could not be inspected (requires root)")
+)
+
+var (
+	diskTimeout = DefaultDiskTimeout
+	// Mountpoints whose usage call has not returned yet. A hung statfs on a
+	// stale network mount never does, so the mount is not asked again
+	// until it answers; otherwise every refresh would leak a goroutine.
+	pendingUsage   = make(map[string]bool)
+	pendingUsageMu sync.Mutex
+)
+
+// SetDiskTimeout sets how long GetSystemStats waits for each filesystem's
+// usage before marking it unresponsive; 0 or less waits indefinitely
+func SetDiskTimeout(timeout time.Duration) {
+	diskTimeout = timeout
+}
+
+// diskUsage is disk.UsageWithContext bounded by the disk timeout. The call
+// blocks in the kernel and ignores ctx, so it runs in its own goroutine.
+func diskUsage(ctx context.Context, mountpoint string) (*disk.UsageStat, error) {
+	if diskTimeout <= 0 {
+		return disk.UsageWithContext(ctx, mountpoint)
+	}
+
+	pendingUsageMu.Lock()
+	if pendingUsage[mountpoint] {
+		pendingUsageMu.Unlock()
+		return nil, ErrDiskTimeout
+	}
+	pendingUsage[mountpoint] = true
+	pendingUsageMu.Unlock()
+
+	type result struct {
+		usage *disk.UsageStat
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		usage, err := disk.UsageWithContext(ctx, mountpoint)
+		pendingUsageMu.Lock()
+		delete(pendingUsage, mountpoint)
+		pendingUsageMu.Unlock()
+		done <- result{usage, err}
+	}()
+
+	timer := time.NewTimer(diskTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.usage, r.err
+	case <-timer.C:
+		return nil, ErrDiskTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func getHostInfo(ctx context.Context) (HostInfo, error) {
+	hostStat, err := host.InfoWithContext(ctx)
+	if err != nil {
+		return HostInfo{}, err
+	}
+
+	return HostInfo{
+		Hostname:      hostStat.Hostname,
+		OS:            hostStat.OS,
+		Platform:      hostStat.Platform,
+		KernelVersion: hostStat.KernelVersion,
+		Uptime:        hostStat.Uptime,
+		BootTime:      hostStat.BootTime,
+		VirtSystem:    hostStat.VirtualizationSystem,
+		VirtRole:      hostStat.VirtualizationRole,
+	}, nil
+}
+
+// DefaultExcludedFstypes lists pseudo and virtual filesystems hidden from
+// disk displays by default
+var DefaultExcludedFstypes = []string{"tmpfs", "devtmpfs", "overlay", "squashfs", "proc", "sysfs"}
+
+// FilterDisks returns the disks whose filesystem type is not in excludeFstypes
+func FilterDisks(disks []DiskInfo, excludeFstypes []string) []DiskInfo {
+	excluded := make(map[string]bool, len(excludeFstypes))
+	for _, fstype := range excludeFstypes {
+		excluded[fstype] = true
+	}
+
+	var filtered []DiskInfo
+	for _, disk := range disks {
+		if !excluded[disk.Fstype] {
+			filtered = append(filtered, disk)
+		}
+	}
+	return filtered
+}
+
+// TempUnit selects how temperatures are displayed
+type TempUnit int
+
+const (
+	Celsius TempUnit = iota
+	Fahrenheit
+)
+
+// ParseTempUnit accepts "c"/"celsius" or "f"/"fahrenheit", case-insensitively
+func ParseTempUnit(value string) (TempUnit, error) {
+	switch strings.ToLower(value) {
+	case "c", "celsius":
+		return Celsius, nil
+	case "f", "fahrenheit":
+		return Fahrenheit, nil
+	}
+	return Celsius, fmt.Errorf("unknown temperature unit %q", value)
+}
+
+// Helper functions for formatting
+
+// FormatBytes formats a byte count with binary (1024-based) units: KiB, MiB, ...
+func FormatBytes(bytes uint64) string {
+	return formatBytes(bytes, 1024, "iB")
+}
+
+// FormatBytesSI formats a byte count with decimal (1000-based) units: KB, MB, ...
+func FormatBytesSI(bytes uint64) string {
+	return formatBytes(bytes, 1000, "B")
+}
+
+// formatBytes scales bytes by unit, clamping at the exa prefix so values
+// beyond it are shown as a large number of EiB/EB rather than overflowing
+func formatBytes(bytes, unit uint64, suffix string) string {
+	const prefixes = "KMGTPE"
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := unit, 0
+	for n := bytes / unit; n >= unit && exp < len(prefixes)-1; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %c%s", float64(bytes)/float64(div), prefixes[exp], suffix)
+}
+
+// FormatTemperature formats a Celsius reading in the requested unit,
+// rounded to whole degrees
+func FormatTemperature(celsius float64, unit TempUnit) string {
+	if unit == Fahrenheit {
+		return fmt.Sprintf("%.0f°F", wholeDegrees(celsius*9/5+32))
+	}
+	return fmt.Sprintf("%.0f°C", wholeDegrees(celsius))
+}
+
+// wholeDegrees rounds halves away from zero, and readings just below zero
+// to 0 rather than -0
+func wholeDegrees(degrees float64) float64 {
+	rounded := math.Round(degrees)
+	if rounded == 0 {
+		return 0
+	}
+	return rounded
+}
+
+func FormatUptime(seconds uint64) string {
+	duration := time.Duration(seconds) * time.Second
+	days := int(duration.Hours()) / 24
+	hours := int(duration.Hours()) % 24
+	minutes := int(duration.Minutes()) % 60
+
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// FormatBootTime formats a Unix boot time in local time, e.g.
+// "2024-01-15 08:32:10", or "unknown" when it is not available
+func FormatBootTime(epochSeconds uint64) string {
+	if epochSeconds == 0 {
+		return "unknown"
+	}
+	return time.Unix(int64(epochSeconds), 0).Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes uint64
+		want  string
+	}{
+		{0, "0 B"},
+		{1, "1 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{1024*1024 - 1, "1024.0 KiB"},
+		{1024 * 1024, "1.0 MiB"},
+		{5 << 30, "5.0 GiB"},
+		{1 << 40, "1.0 TiB"},
+		{1 << 50, "1.0 PiB"},
+		{1 << 60, "1.0 EiB"},
+		{3 << 61, "6.0 EiB"},
+		{math.MaxUint64, "16.0 EiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.bytes); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestFormatBytesSI(t *testing.T) {
+	tests := []struct {
+		bytes uint64
+		want  string
+	}{
+		{0, "0 B"},
+		{999, "999 B"},
+		{1000, "1.0 KB"},
+		{1023, "1.0 KB"},
+		{1024, "1.0 KB"},
+		{1_500_000, "1.5 MB"},
+		{1_000_000_000_000_000_000, "1.0 EB"},
+		{math.MaxUint64, "18.4 EB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytesSI(tt.bytes); got != tt.want {
+			t.Errorf("FormatBytesSI(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTemperature(t *testing.T) {
+	tests := []struct {
+		celsius float64
+		unit    TempUnit
+		want    string
+	}{
+		{0, Celsius, "0°C"},
+		{0, Fahrenheit, "32°F"},
+		{100, Celsius, "100°C"},
+		{100, Fahrenheit, "212°F"},
+		{-40, Celsius, "-40°C"},
+		{-40, Fahrenheit, "-40°F"}, // where the scales cross
+		{-0.4, Celsius, "0°C"},
+		{-17.9, Fahrenheit, "0°F"}, // -0.22°F
+		{-0.5, Celsius, "-1°C"},
+		{0.5, Celsius, "1°C"},
+		{2.5, Celsius, "3°C"},
+		{36.6, Celsius, "37°C"},
+		{37, Fahrenheit, "99°F"}, // 98.6°F
+		{-273.15, Celsius, "-273°C"},
+		{-273.15, Fahrenheit, "-460°F"},
+	}
+	for _, tt := range tests {
+		if got := FormatTemperature(tt.celsius, tt.unit); got != tt.want {
+			t.Errorf("FormatTemperature(%v, %v) = %q, want %q", tt.celsius, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestParseTempUnit(t *testing.T) {
+	for _, value := range []string{"c", "C", "celsius", "Celsius"} {
+		if unit, err := ParseTempUnit(value); err != nil || unit != Celsius {
+			t.Errorf("ParseTempUnit(%q) = %v, %v, want Celsius", value, unit, err)
+		}
+	}
+	for _, value := range []string{"f", "F", "fahrenheit", "FAHRENHEIT"} {
+		if unit, err := ParseTempUnit(value); err != nil || unit != Fahrenheit {
+			t.Errorf("ParseTempUnit(%q) = %v, %v, want Fahrenheit", value, unit, err)
+		}
+	}
+	for _, value := range []string{"", "k", "kelvin"} {
+		if _, err := ParseTempUnit(value); err == nil {
+			t.Errorf("ParseTempUnit(%q) succeeded, want an error", value)
+		}
+	}
+}
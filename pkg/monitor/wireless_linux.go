@@ -0,0 +1,58 @@
+//go:build linux
+// +build linux
+
+// pkg/monitor/wireless_linux.go
+
+package monitor
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// wirelessSignals returns the signal level in dBm of each wireless
+// interface listed in /proc/net/wireless. Interfaces whose driver reports
+// no usable level map to zero. The file is missing when no wireless
+// drivers are loaded, in which case the result is empty.
+func wirelessSignals() map[string]int {
+	file, err := os.Open("/proc/net/wireless")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	// Two header lines, then "wlan0: 0000   54.  -56.  -256 ..." with the
+	// status, link quality, signal level and noise level
+	signals := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	for line := 0; scanner.Scan(); line++ {
+		if line < 2 {
+			continue
+		}
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		signals[strings.TrimSpace(name)] = 0
+
+		fields := strings.Fields(rest)
+		if len(fields) < 3 {
+			continue
+		}
+		level, err := strconv.ParseFloat(strings.TrimSuffix(fields[2], "."), 64)
+		if err != nil {
+			continue
+		}
+		// Some drivers report dBm as an unsigned byte; small positive
+		// values are a relative level, not dBm
+		if level >= 128 {
+			level -= 256
+		}
+		if level < 0 {
+			signals[strings.TrimSpace(name)] = int(level)
+		}
+	}
+	return signals
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+// pkg/monitor/wireless_other.go
+
+package monitor
+
+// wirelessSignals is only implemented on Linux; elsewhere every interface
+// reports as wired
+func wirelessSignals() map[string]int {
+	return nil
+}
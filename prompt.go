@@ -0,0 +1,79 @@
+// prompt.go - Modal input in the footer
+package main
+
+// promptLine asks for a line of text in the footer, echoing it as it is
+// typed. Refreshes stop until Enter accepts the line or Esc cancels it;
+// ok is false when cancelled.
+func (app *App) promptLine(label string) (line string, ok bool) {
+	app.startPrompt(label)
+	defer app.endPrompt()
+
+	for {
+		event, ok := app.nextPromptKey()
+		if !ok {
+			return "", false
+		}
+		switch event.Key {
+		case KeyEnter:
+			return string(app.promptInput), true
+		case KeyEscape:
+			return "", false
+		case KeyBackspace:
+			if len(app.promptInput) > 0 {
+				app.promptInput = app.promptInput[:len(app.promptInput)-1]
+			}
+		case KeyRune:
+			app.promptInput = append(app.promptInput, event.Rune)
+		}
+		app.displayInterface()
+	}
+}
+
+// promptYesNo asks a yes/no question in the footer and waits for the
+// answer. Esc, n, or the end of input count as no.
+func (app *App) promptYesNo(question string) bool {
+	app.startPrompt(question + " (y/n)")
+	defer app.endPrompt()
+
+	for {
+		event, ok := app.nextPromptKey()
+		if !ok {
+			return false
+		}
+		switch {
+		case event.Key == KeyEscape:
+			return false
+		case event.Key != KeyRune:
+		case event.Rune == 'y' || event.Rune == 'Y':
+			return true
+		case event.Rune == 'n' || event.Rune == 'N':
+			return false
+		}
+	}
+}
+
+func (app *App) startPrompt(label string) {
+	app.prompt, app.promptInput = label, nil
+	app.displayInterface()
+}
+
+func (app *App) endPrompt() {
+	app.prompt, app.promptInput = "", nil
+	app.displayInterface()
+}
+
+// nextPromptKey waits for a key press, bypassing the main loop. It returns
+// false when input has ended or the program is shutting down. Functions
+// passed to Do keep running meanwhile, as the main loop is blocked.
+func (app *App) nextPromptKey() (KeyEvent, bool) {
+	for {
+		select {
+		case <-app.ctx.Done():
+			return KeyEvent{}, false
+		case event, ok := <-app.input:
+			return event, ok
+		case fn := <-app.commands:
+			fn()
+		}
+	}
+}
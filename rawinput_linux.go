@@ -0,0 +1,12 @@
+//go:build linux
+// +build linux
+
+// rawinput_linux.go
+package main
+
+import "syscall"
+
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+// rawinput_other.go
+package main
+
+import "os"
+
+// enableRawInput is a no-op here; keys are read a line at a time and
+// arrive after Enter
+func enableRawInput(f *os.File) (func(), error) {
+	return func() {}, nil
+}
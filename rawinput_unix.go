@@ -0,0 +1,45 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+// rawinput_unix.go - Unbuffered keyboard input on Unix terminals
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/term"
+)
+
+// enableRawInput turns off line buffering and echo on a terminal so each
+// key press is read as it happens. Output processing and signal keys are
+// left alone, so newlines still return the cursor and Ctrl-C still
+// interrupts. The returned function restores the previous settings.
+func enableRawInput(f *os.File) (func(), error) {
+	fd := f.Fd()
+	if !term.IsTerminal(int(fd)) {
+		return func() {}, nil
+	}
+
+	var saved syscall.Termios
+	if err := termios(fd, ioctlGetTermios, &saved); err != nil {
+		return nil, err
+	}
+	raw := saved
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := termios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+	return func() { termios(fd, ioctlSetTermios, &saved) }, nil
+}
+
+func termios(fd, request uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
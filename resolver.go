@@ -0,0 +1,87 @@
+// resolver.go - Background reverse DNS for connection remote addresses
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reverse lookup limits. Lookups run in the background and a refresh shows
+// whatever has been resolved so far, so a slow resolver never stalls the UI.
+const (
+	resolveTimeout    = 2 * time.Second
+	resolveTTL        = 10 * time.Minute // failed lookups are retried after this too
+	maxResolveCache   = 512
+	maxPendingLookups = 8 // lookups running at once; later addresses wait for a refresh
+)
+
+// hostResolver caches reverse DNS names of IP addresses. It is safe for
+// use from any goroutine.
+type hostResolver struct {
+	ctx     context.Context
+	mu      sync.Mutex
+	cache   map[string]resolvedHost
+	pending map[string]bool
+}
+
+type resolvedHost struct {
+	name string // empty when the address has no PTR record or the lookup failed
+	at   time.Time
+}
+
+func newHostResolver(ctx context.Context) *hostResolver {
+	return &hostResolver{ctx: ctx, cache: make(map[string]resolvedHost), pending: make(map[string]bool)}
+}
+
+// Lookup returns the cached hostname of ip and whether a lookup has
+// finished. Addresses not yet cached, or cached longer than resolveTTL,
+// are looked up in the background.
+func (r *hostResolver) Lookup(ip string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	host, ok := r.cache[ip]
+	if ok && time.Since(host.at) < resolveTTL {
+		return host.name, true
+	}
+	if !r.pending[ip] && len(r.pending) < maxPendingLookups {
+		r.pending[ip] = true
+		go r.resolve(ip)
+	}
+	return host.name, ok
+}
+
+func (r *hostResolver) resolve(ip string) {
+	ctx, cancel := context.WithTimeout(r.ctx, resolveTimeout)
+	defer cancel()
+	var name string
+	if names, err := net.DefaultResolver.LookupAddr(ctx, ip); err == nil && len(names) > 0 {
+		name = strings.TrimSuffix(names[0], ".")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, ip)
+	if r.ctx.Err() != nil {
+		return
+	}
+	if _, ok := r.cache[ip]; !ok && len(r.cache) >= maxResolveCache {
+		r.evictOldest()
+	}
+	r.cache[ip] = resolvedHost{name: name, at: time.Now()}
+}
+
+// evictOldest drops the entry resolved longest ago
+func (r *hostResolver) evictOldest() {
+	var oldest string
+	var oldestAt time.Time
+	for ip, host := range r.cache {
+		if oldest == "" || host.at.Before(oldestAt) {
+			oldest, oldestAt = ip, host.at
+		}
+	}
+	delete(r.cache, oldest)
+}
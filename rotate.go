@@ -0,0 +1,232 @@
+// rotate.go - Size-based log file rotation
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RotatingWriter writes to timestamped files in a directory. Once the active
+// file would grow beyond maxSize bytes it is closed and a new one is opened;
+// only the newest maxFiles files created by the writer are kept. With
+// compression enabled, closed files are gzipped in the background while the
+// active file stays plain text for tailing.
+type RotatingWriter struct {
+	dir      string
+	prefix   string
+	maxSize  int64 // <= 0 disables rotation
+	maxFiles int   // <= 0 keeps every file
+	compress bool
+
+	file     *os.File
+	size     int64
+	files    []string // Files created by this writer, oldest first; only kept for pruning
+	lastBase string   // Timestamp part of the newest file name
+	lastSeq  int      // Counter appended to lastBase
+
+	compressing map[string]chan struct{} // Closed when that file's compression finishes; only kept for pruning
+	wg          sync.WaitGroup
+}
+
+// NewRotatingWriter creates dir if needed and opens the first log file
+func NewRotatingWriter(dir, prefix string, maxSize int64, maxFiles int, compress bool) (*RotatingWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	w := &RotatingWriter{
+		dir:         dir,
+		prefix:      prefix,
+		maxSize:     maxSize,
+		maxFiles:    maxFiles,
+		compress:    compress,
+		compressing: make(map[string]chan struct{}),
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write appends p to the active file, rotating first if p would push the
+// file past maxSize. A single write is never split across files.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Name returns the path of the active file
+func (w *RotatingWriter) Name() string {
+	if w.file == nil {
+		return ""
+	}
+	return w.file.Name()
+}
+
+// Close closes the active file and waits for any background compression of
+// rotated files to finish
+func (w *RotatingWriter) Close() error {
+	err := w.closeFile()
+	w.wg.Wait()
+	return err
+}
+
+// closeFile closes the active file without waiting for compression
+func (w *RotatingWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate closes the active file, opens a fresh one and prunes old files.
+// Failing to prune is only logged, as the new file is ready for writing.
+func (w *RotatingWriter) rotate() error {
+	closed := w.Name()
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+
+	if w.compress {
+		done := make(chan struct{})
+		if w.maxFiles > 0 {
+			w.compressing[closed] = done
+		}
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			defer close(done)
+			if err := gzipFile(closed); err != nil {
+				log.Printf("Error compressing log file %s: %v", closed, err)
+			}
+		}()
+	}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+	if err := w.prune(); err != nil {
+		log.Printf("Error pruning old log files: %v", err)
+	}
+	return nil
+}
+
+// open creates a new timestamped file, adding a counter if a file with the
+// same timestamp already exists
+func (w *RotatingWriter) open() error {
+	base := fmt.Sprintf("%s_%s", w.prefix, time.Now().Format("20060102_150405"))
+
+	// Counters only grow within a timestamp so pruned names aren't reused
+	seq := 0
+	if base == w.lastBase {
+		seq = w.lastSeq + 1
+	}
+	for w.nameTaken(w.filename(base, seq)) {
+		seq++
+	}
+	filename := w.filename(base, seq)
+	w.lastBase, w.lastSeq = base, seq
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	w.file = file
+	w.size = 0
+	if w.maxFiles > 0 {
+		w.files = append(w.files, filename)
+	}
+	return nil
+}
+
+// filename builds the path for the seq-th file sharing a timestamp
+func (w *RotatingWriter) filename(base string, seq int) string {
+	if seq == 0 {
+		return filepath.Join(w.dir, base+".log")
+	}
+	return filepath.Join(w.dir, fmt.Sprintf("%s_%d.log", base, seq))
+}
+
+// nameTaken reports whether filename, or its compressed form, exists
+func (w *RotatingWriter) nameTaken(filename string) bool {
+	for _, name := range []string{filename, filename + ".gz"} {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// prune deletes the oldest files beyond maxFiles
+func (w *RotatingWriter) prune() error {
+	if w.maxFiles <= 0 || len(w.files) <= w.maxFiles {
+		return nil
+	}
+
+	excess := len(w.files) - w.maxFiles
+	for _, filename := range w.files[:excess] {
+		// Let an in-flight compression finish so it can't recreate the file
+		if done, ok := w.compressing[filename]; ok {
+			<-done
+			delete(w.compressing, filename)
+		}
+		for _, name := range []string{filename, filename + ".gz"} {
+			if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	w.files = w.files[excess:]
+	return nil
+}
+
+// gzipFile compresses path to path.gz and removes the original. On failure
+// the original is left in place.
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	_, err = io.Copy(gz, src)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
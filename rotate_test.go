@@ -0,0 +1,108 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLines writes each line to w in a single Write
+func writeLines(t *testing.T, w *RotatingWriter, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if n, err := w.Write([]byte(line)); err != nil || n != len(line) {
+			t.Fatalf("Write(%q) = %d, %v", line, n, err)
+		}
+	}
+}
+
+// readLogs returns the contents of the files in dir in name order, which is
+// creation order, decompressing .gz files
+func readLogs(t *testing.T, dir string) []string {
+	t.Helper()
+	var contents []string
+	for _, name := range dirNames(t, dir) {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r io.Reader = file
+		if strings.HasSuffix(name, ".gz") {
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			r = gz
+		}
+		data, err := io.ReadAll(r)
+		file.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		contents = append(contents, string(data))
+	}
+	return contents
+}
+
+func TestRotatingWriterRotates(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewRotatingWriter(dir, "sysmon", 10, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, w, "first\n", "second\n", "3\n", "fourth line\n")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A write is never split, even one longer than the limit
+	got := readLogs(t, dir)
+	want := []string{"first\n", "second\n3\n", "fourth line\n"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("files hold %q, want %q", got, want)
+	}
+	if len(w.files) != 0 || len(w.compressing) != 0 {
+		t.Errorf("kept %d files and %d compressions without maxFiles", len(w.files), len(w.compressing))
+	}
+}
+
+func TestRotatingWriterPrunes(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewRotatingWriter(dir, "sysmon", 4, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, w, "one\n", "two\n", "three\n", "four\n")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readLogs(t, dir)
+	if want := []string{"three\n", "four\n"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("after pruning to 2 files: %q, want %q", got, want)
+	}
+}
+
+func TestRotatingWriterCompresses(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewRotatingWriter(dir, "sysmon", 4, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeLines(t, w, "one\n", "two\n", "three\n")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	names := dirNames(t, dir)
+	if len(names) != 2 || !strings.HasSuffix(names[0], ".log.gz") || !strings.HasSuffix(names[1], ".log") {
+		t.Fatalf("want a gzipped rotated file and the plain active one, got %v", names)
+	}
+	got := readLogs(t, dir)
+	if want := []string{"two\n", "three\n"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("files hold %q, want %q", got, want)
+	}
+}
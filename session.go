@@ -0,0 +1,108 @@
+// session.go - Running statistics over a logging session
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// RunningStats keeps the minimum, maximum and mean of a series without
+// storing it. The mean is updated incrementally (Welford), which stays
+// accurate over long sessions where a running sum would lose precision.
+type RunningStats struct {
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+}
+
+// Add records one sample
+func (r *RunningStats) Add(value float64) {
+	r.Count++
+	if r.Count == 1 {
+		r.Min, r.Max, r.Mean = value, value, value
+		return
+	}
+	r.Min = min(r.Min, value)
+	r.Max = max(r.Max, value)
+	r.Mean += (value - r.Mean) / float64(r.Count)
+}
+
+// SessionSummary aggregates the samples written while logging
+type SessionSummary struct {
+	Started time.Time
+	Samples int
+	CPU     RunningStats
+	Memory  RunningStats
+	Swap    RunningStats
+	// Busiest interface seen, by upload plus download
+	PeakNetKBps  float64
+	PeakNetIface string
+}
+
+func newSessionSummary(started time.Time) *SessionSummary {
+	return &SessionSummary{Started: started}
+}
+
+// Add folds in one logged sample and the interface speeds measured with it.
+// Sections that failed to collect are left out rather than counted as zero.
+func (s *SessionSummary) Add(stats *internal.SystemStats, speeds []internal.NetworkSpeed) {
+	s.Samples++
+	if _, failed := stats.Errors["cpu"]; !failed {
+		s.CPU.Add(stats.CPU.Usage)
+	}
+	if _, failed := stats.Errors["memory"]; !failed { // swap comes with memory
+		s.Memory.Add(stats.Memory.UsedPercent)
+		s.Swap.Add(stats.Memory.SwapUsedPercent)
+	}
+	for _, speed := range speeds {
+		if total := speed.UploadKBps + speed.DownloadKBps; total > s.PeakNetKBps {
+			s.PeakNetKBps, s.PeakNetIface = total, speed.Interface
+		}
+	}
+}
+
+func (app *App) displaySessionSummary() {
+	fmt.Fprintf(&app.frame, "%s%s Logging Session Summary%s\n", app.colorize("", ColorBold+ColorYellow), app.icon("📋"), app.colorize("", ColorReset))
+	fmt.Fprintln(&app.frame, app.colorize(strings.Repeat("─", 80), ColorDim))
+
+	session := app.session
+	if session == nil || session.Samples == 0 {
+		fmt.Fprintln(&app.frame, app.colorize("  No samples yet. Press L to start logging.", ColorDim))
+	} else {
+		state := "stopped"
+		if app.logToFile {
+			state = "logging"
+		}
+		fmt.Fprintf(&app.frame, "  Started:   %s (%s)\n", app.colorize(session.Started.Format("2006-01-02 15:04:05"), ColorCyan), state)
+		fmt.Fprintf(&app.frame, "  Duration:  %s\n", app.colorize(time.Since(session.Started).Round(time.Second).String(), ColorCyan))
+		fmt.Fprintf(&app.frame, "  Samples:   %s\n\n", app.colorize(fmt.Sprintf("%d", session.Samples), ColorCyan))
+
+		fmt.Fprintf(&app.frame, "  %-10s %8s %8s %8s\n", "", "MIN", "AVG", "MAX")
+		for _, row := range []struct {
+			name  string
+			stats RunningStats
+		}{{"CPU", session.CPU}, {"Memory", session.Memory}, {"Swap", session.Swap}} {
+			if row.stats.Count == 0 {
+				fmt.Fprintf(&app.frame, "  %-10s %s\n", row.name, app.colorize("not collected", ColorDim))
+				continue
+			}
+			fmt.Fprintf(&app.frame, "  %-10s %s %s %s\n", row.name,
+				app.colorize(fmt.Sprintf("%7.1f%%", row.stats.Min), app.getUsageColor(row.stats.Min)),
+				app.colorize(fmt.Sprintf("%7.1f%%", row.stats.Mean), app.getUsageColor(row.stats.Mean)),
+				app.colorize(fmt.Sprintf("%7.1f%%", row.stats.Max), app.getUsageColor(row.stats.Max)))
+		}
+
+		if session.PeakNetIface != "" {
+			fmt.Fprintf(&app.frame, "\n  Peak network: %s on %s\n",
+				app.colorize(internal.FormatNetworkSpeed(session.PeakNetKBps), ColorYellow),
+				app.colorize(session.PeakNetIface, ColorCyan))
+		}
+	}
+
+	fmt.Fprintln(&app.frame)
+	fmt.Fprint(&app.frame, app.colorize("Press S or Esc to return...", ColorDim))
+}
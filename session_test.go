@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+func TestSessionSummarySkipsFailedSections(t *testing.T) {
+	session := newSessionSummary(time.Now())
+	session.Add(&internal.SystemStats{
+		CPU:    internal.CPUInfo{Usage: 40},
+		Memory: internal.MemoryInfo{UsedPercent: 60, SwapUsedPercent: 10},
+	}, nil)
+	session.Add(&internal.SystemStats{
+		Memory: internal.MemoryInfo{UsedPercent: 80, SwapUsedPercent: 20},
+		Errors: map[string]string{"cpu": "permission denied"},
+	}, nil)
+	session.Add(&internal.SystemStats{
+		CPU:    internal.CPUInfo{Usage: 20},
+		Errors: map[string]string{"memory": "permission denied"},
+	}, nil)
+
+	if session.Samples != 3 {
+		t.Errorf("Samples = %d, want 3", session.Samples)
+	}
+	for _, tt := range []struct {
+		name      string
+		stats     RunningStats
+		count     int
+		min, mean float64
+	}{
+		{"CPU", session.CPU, 2, 20, 30},
+		{"Memory", session.Memory, 2, 60, 70},
+		{"Swap", session.Swap, 2, 10, 15},
+	} {
+		if tt.stats.Count != tt.count || tt.stats.Min != tt.min || tt.stats.Mean != tt.mean {
+			t.Errorf("%s: count %d, min %v, mean %v; want %d, %v, %v",
+				tt.name, tt.stats.Count, tt.stats.Min, tt.stats.Mean, tt.count, tt.min, tt.mean)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// state.go - UI state remembered between runs
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// UIState is what the terminal interface looked like when it was last
+// closed. Unlike the flags it records the last session rather than user
+// intent, so flags given explicitly always win over it.
+type UIState struct {
+	View        ViewType `json:"view"`
+	CompactMode bool     `json:"compact_mode"`
+	Color       string   `json:"color"`        // -color mode
+	Theme       string   `json:"theme"`        // -theme name or file
+	BarStyle    string   `json:"bar_style"`    // -bar-style
+	RefreshRate string   `json:"refresh_rate"` // e.g. "3s"
+	TempUnit    string   `json:"temp_unit"`    // "c" or "f"
+	NoEmoji     bool     `json:"no_emoji"`
+}
+
+// defaultStatePath keeps the state in the user cache directory, e.g.
+// ~/.cache/sysmon/state.json. It is empty, disabling the state file, when
+// there is no cache directory.
+func defaultStatePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sysmon", "state.json")
+}
+
+// LoadState reads a state file. A missing file is not an error and yields
+// nil; a corrupt one is reported so the caller can fall back to defaults.
+func LoadState(path string) (*UIState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state UIState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Save writes the state file, creating its directory. The file is replaced
+// atomically so an interrupted write cannot leave it corrupt.
+func (s *UIState) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// applyTo copies the remembered settings into opts, except those whose
+// flags were set explicitly on the command line
+func (s *UIState) applyTo(opts *Options) {
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	if s.Color != "" && !explicit["color"] {
+		opts.Color = s.Color
+	}
+	if s.Theme != "" && !explicit["theme"] {
+		opts.Theme = s.Theme
+	}
+	if s.BarStyle != "" && !explicit["bar-style"] {
+		opts.BarStyle = s.BarStyle
+	}
+	if s.TempUnit != "" && !explicit["temp-unit"] {
+		opts.TempUnit = s.TempUnit
+	}
+	if !explicit["no-emoji"] {
+		opts.NoEmoji = s.NoEmoji
+	}
+	if rate, err := time.ParseDuration(s.RefreshRate); err == nil && !explicit["refresh"] {
+		opts.RefreshRate = rate
+	}
+}
+
+// uiState captures the current interface settings for the state file
+func (app *App) uiState() *UIState {
+	state := &UIState{
+		View:        app.currentView,
+		CompactMode: app.compactMode,
+		Color:       app.colorMode,
+		Theme:       app.theme.Name,
+		BarStyle:    app.barStyle.String(),
+		RefreshRate: app.refreshRate.String(),
+		TempUnit:    "c",
+		NoEmoji:     app.noEmoji,
+	}
+	if app.tempUnit == internal.Fahrenheit {
+		state.TempUnit = "f"
+	}
+	return state
+}
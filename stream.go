@@ -0,0 +1,157 @@
+// stream.go - Headless JSON output modes
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// runHeadless handles the -once and -stream modes, which write
+// newline-delimited JSON (or a Nagios check line) to stdout, -once with
+// -export-formats, which writes export files, and the -serve agent mode,
+// all without starting a UI. It reports whether one of those
+// modes was requested.
+func runHeadless(opts *Options) bool {
+	switch strings.ToLower(opts.Format) {
+	case "", "json":
+	case "nagios":
+		if opts.Stream {
+			fmt.Fprintln(os.Stderr, "sysmon: -format nagios is a single check and cannot be used with -stream")
+			os.Exit(nagiosUnknown)
+		}
+		opts.Once = true
+	default:
+		fmt.Fprintf(os.Stderr, "sysmon: unknown -format %q, want json or nagios\n", opts.Format)
+		os.Exit(2)
+	}
+	if !opts.Once && !opts.Stream && opts.Serve == "" {
+		return false
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// CPU usage is averaged since the previous collection, so take a
+	// baseline shortly before the first sample
+	internal.GetSystemStats(ctx)
+	select {
+	case <-ctx.Done():
+		return true
+	case <-time.After(time.Second):
+	}
+
+	var err error
+	switch {
+	case opts.Serve != "":
+		err = serveSamples(ctx, opts.Serve)
+	case opts.Once && strings.EqualFold(opts.Format, "nagios"):
+		os.Exit(writeCheck(ctx, os.Stdout, opts))
+	case opts.Once && opts.ExportFormats != "":
+		err = exportOnce(ctx, os.Stdout, opts)
+	case opts.Once:
+		err = writeSample(ctx, os.Stdout, nil)
+	default:
+		err = streamSamples(ctx, os.Stdout, opts.RefreshRate)
+	}
+	if err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "sysmon: %v\n", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// streamSamples writes a sample every interval until ctx is cancelled,
+// flushing after each line so pipeline consumers see it immediately
+func streamSamples(ctx context.Context, w io.Writer, interval time.Duration) error {
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var clock internal.ClockWatch
+	for {
+		if err := writeSample(ctx, w, &clock); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeSample collects stats once and writes them as a single JSON line.
+// With a clock, a step of the wall clock since its previous sample is
+// flagged in the entry.
+func writeSample(ctx context.Context, w io.Writer, clock *internal.ClockWatch) error {
+	stats, err := internal.GetSystemStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	procStats, _ := internal.GetProcessStats(ctx)
+	netStats, _ := internal.GetNetworkStats(ctx)
+
+	entry := newLogEntry(stats, procStats, netStats)
+	if clock != nil {
+		markClockJump(entry, clock.Check(time.Now()))
+	}
+	buf := bufio.NewWriter(w)
+	if err := json.NewEncoder(buf).Encode(entry); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// serveSamples answers GET /stats with a fresh sample in the -once format,
+// for -hosts dashboards on other machines, until ctx is cancelled
+func serveSamples(ctx context.Context, addr string) error {
+	server := &http.Server{Addr: addr, Handler: sampleHandler(), ReadHeaderTimeout: hostTimeout}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// sampleHandler serves GET /stats. The collectors keep their previous
+// sample in package state and are not safe for concurrent use, so
+// requests from several dashboards take turns collecting; each response
+// is buffered so a slow client does not hold up the others.
+func sampleHandler() http.Handler {
+	var collecting sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		var sample bytes.Buffer
+		collecting.Lock()
+		err := writeSample(r.Context(), &sample, nil)
+		collecting.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(sample.Bytes())
+	})
+	return mux
+}
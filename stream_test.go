@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Dashboards poll agents independently, so requests overlap. Run with
+// -race: the collectors' package state must only be touched by one
+// request at a time.
+func TestSampleHandlerConcurrentRequests(t *testing.T) {
+	server := httptest.NewServer(sampleHandler())
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(server.URL + "/stats")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status %d, want 200", resp.StatusCode)
+				return
+			}
+			var sample map[string]json.RawMessage
+			if err := json.NewDecoder(resp.Body).Decode(&sample); err != nil {
+				t.Errorf("decoding sample: %v", err)
+				return
+			}
+			if _, ok := sample["system"]; !ok {
+				t.Error("sample has no system stats")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,142 @@
+// theme.go - Terminal color themes
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Theme maps semantic display roles to ANSI escape sequences
+type Theme struct {
+	Name   string
+	Low    string // Usage below the warning threshold
+	Medium string // Usage between the warning and critical thresholds
+	High   string // Usage above the critical threshold
+	Border string // Header and footer box drawing
+	Header string // Title text
+}
+
+// Built-in themes
+var (
+	DefaultTheme = Theme{
+		Name:   "default",
+		Low:    ColorGreen,
+		Medium: ColorYellow,
+		High:   ColorRed,
+		Border: ColorCyan,
+		Header: ColorBold + ColorWhite,
+	}
+
+	// ColorblindTheme uses a blue to orange scale that stays distinguishable
+	// with red-green color vision deficiencies
+	ColorblindTheme = Theme{
+		Name:   "colorblind",
+		Low:    ColorBlue,
+		Medium: "\033[38;5;214m", // Light orange
+		High:   ColorBold + "\033[38;5;202m",
+		Border: ColorCyan,
+		Header: ColorBold + ColorWhite,
+	}
+)
+
+// colorNames maps the names accepted in theme files to ANSI sequences
+var colorNames = map[string]string{
+	"red":    ColorRed,
+	"green":  ColorGreen,
+	"yellow": ColorYellow,
+	"blue":   ColorBlue,
+	"purple": ColorPurple,
+	"cyan":   ColorCyan,
+	"white":  ColorWhite,
+	"bold":   ColorBold,
+	"dim":    ColorDim,
+	"orange": "\033[38;5;214m",
+}
+
+// LoadTheme returns the built-in theme with the given name, or otherwise
+// reads a JSON theme file. Theme files map roles to color specs such as
+// "blue", "bold+red" or a raw SGR parameter like "38;5;214"; roles left out
+// keep their default colors.
+func LoadTheme(nameOrPath string) (Theme, error) {
+	switch nameOrPath {
+	case "", DefaultTheme.Name:
+		return DefaultTheme, nil
+	case ColorblindTheme.Name:
+		return ColorblindTheme, nil
+	}
+
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return DefaultTheme, fmt.Errorf("unknown theme %q: %w", nameOrPath, err)
+	}
+
+	var specs map[string]string
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return DefaultTheme, fmt.Errorf("invalid theme file %s: %w", nameOrPath, err)
+	}
+
+	theme := DefaultTheme
+	theme.Name = nameOrPath
+	roles := map[string]*string{
+		"low":    &theme.Low,
+		"medium": &theme.Medium,
+		"high":   &theme.High,
+		"border": &theme.Border,
+		"header": &theme.Header,
+	}
+	for role, spec := range specs {
+		target, ok := roles[role]
+		if !ok {
+			return DefaultTheme, fmt.Errorf("invalid theme file %s: unknown role %q", nameOrPath, role)
+		}
+		color, err := parseColorSpec(spec)
+		if err != nil {
+			return DefaultTheme, fmt.Errorf("invalid theme file %s: %w", nameOrPath, err)
+		}
+		*target = color
+	}
+
+	return theme, nil
+}
+
+// parseColorSpec converts a "+"-separated list of color names or SGR
+// parameters into an ANSI escape sequence
+func parseColorSpec(spec string) (string, error) {
+	var color string
+	for _, part := range strings.Split(spec, "+") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if code, ok := colorNames[part]; ok {
+			color += code
+			continue
+		}
+		if part == "" || strings.Trim(part, "0123456789;") != "" {
+			return "", fmt.Errorf("unknown color %q", part)
+		}
+		color += "\033[" + part + "m"
+	}
+	return color, nil
+}
+
+// UseColor resolves a -color mode. "always" and "never" are explicit; "auto"
+// disables color when NO_COLOR is set (to any value) or stdout is not a
+// terminal.
+func UseColor(mode string) (bool, error) {
+	switch strings.ToLower(mode) {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "", "auto":
+	default:
+		return false, fmt.Errorf("unknown color mode %q", mode)
+	}
+
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false, nil
+	}
+	return term.IsTerminal(int(os.Stdout.Fd())), nil
+}
@@ -0,0 +1,130 @@
+// urgent.go - Jumping to the view of the most concerning metric
+package main
+
+import (
+	"fmt"
+
+	"github.com/imunderthetree/sysmon/internal"
+)
+
+// urgentLevel is a metric over its warning level and the view showing it
+type urgentLevel struct {
+	Level
+	View       ViewType
+	Reason     string
+	Mountpoint string // the filesystem to select in the Disks view
+}
+
+// outranks orders levels by severity, then by how close they are to (or
+// how far past) their critical level
+func (u urgentLevel) outranks(other urgentLevel) bool {
+	severity, _ := u.Severity()
+	otherSeverity, _ := other.Severity()
+	if severity != otherSeverity {
+		return severity > otherSeverity
+	}
+	return u.Value/u.Crit > other.Value/other.Crit
+}
+
+// mostUrgentView picks the view with the most concerning metric: a full or
+// unresponsive filesystem leads to Disks, busy CPU or memory to Processes,
+// and a saturated interface to Network. CPU and memory are judged against
+// the display thresholds, filesystems and memory pressure against the
+// levels of the resource alerts, and interfaces by their share of the link
+// speed. It returns false when nothing is over its warning level.
+func (app *App) mostUrgentView(stats *internal.SystemStats, procs *internal.ProcessStats, netStats *internal.NetworkStats) (urgentLevel, bool) {
+	var candidates []urgentLevel
+	// Filesystems are judged below, where unresponsive ones count too
+	for _, level := range checkLevels(stats, nil, app.warnThreshold, app.critThreshold) {
+		candidate := urgentLevel{Level: level, View: ViewProcesses}
+		switch level.Name {
+		case "cpu":
+			candidate.Reason = fmt.Sprintf("CPU at %.0f%%", level.Value)
+			if procs != nil && len(procs.TopCPU) > 0 {
+				candidate.Reason += ", top: " + procs.TopCPU[0].Name
+			}
+		case "mem":
+			candidate.Reason = fmt.Sprintf("memory at %.0f%%", level.Value)
+			if procs != nil && len(procs.TopMemory) > 0 {
+				candidate.Reason += ", top: " + procs.TopMemory[0].Name
+			}
+		default:
+			candidate.Reason = fmt.Sprintf("memory pressure at %.0f%%", level.Value)
+		}
+		candidates = append(candidates, candidate)
+	}
+	if _, failed := stats.Errors["disk"]; !failed {
+		for _, disk := range internal.FilterDisks(stats.Disk, app.excludedFstypes) {
+			candidate := urgentLevel{Level: diskLevel(disk), View: ViewDisks, Mountpoint: disk.Mountpoint,
+				Reason: fmt.Sprintf("%s is %.1f%% full", disk.Mountpoint, disk.UsedPercent)}
+			if disk.Unresponsive {
+				// Worse than full: whatever reads it hangs
+				candidate.Value = 2 * candidate.Crit
+				candidate.Reason = disk.Mountpoint + " is not responding"
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+	if netStats != nil {
+		linkSpeeds := make(map[string]uint64, len(netStats.Interfaces))
+		for _, iface := range netStats.Interfaces {
+			linkSpeeds[iface.Name] = iface.Speed
+		}
+		for _, speed := range app.netSpeeds {
+			mbps := linkSpeeds[speed.Interface]
+			if mbps == 0 {
+				continue // unknown link speed, as on most virtual interfaces
+			}
+			// Links are full duplex, so the busier direction is what saturates
+			percent := max(speed.UploadKBps, speed.DownloadKBps) * 8 / 1000 / float64(mbps) * 100
+			candidates = append(candidates, urgentLevel{
+				Level:  Level{Name: "net_" + speed.Interface, Value: percent, Warn: app.warnThreshold, Crit: app.critThreshold},
+				View:   ViewNetwork,
+				Reason: fmt.Sprintf("%s at %.0f%% of its %d Mbps link", speed.Interface, percent, mbps),
+			})
+		}
+	}
+
+	var urgent urgentLevel
+	found := false
+	for _, candidate := range candidates {
+		if _, over := candidate.Severity(); !over {
+			continue
+		}
+		if !found || candidate.outranks(urgent) {
+			urgent, found = candidate, true
+		}
+	}
+	return urgent, found
+}
+
+// jumpToUrgentView switches to the view of the most concerning metric,
+// selecting the filesystem when it is a disk, and says why in the footer
+func (app *App) jumpToUrgentView() {
+	stats, err := app.collector.SystemStats(app.ctx)
+	if err != nil {
+		app.statusMessage = "Cannot judge the system: " + err.Error()
+		app.displayInterface()
+		return
+	}
+	procs, _ := app.processStats()
+	netStats, _ := app.collector.NetworkStats(app.ctx)
+
+	urgent, found := app.mostUrgentView(stats, procs, netStats)
+	if !found {
+		app.statusMessage = "Nothing is over its warning level"
+		app.displayInterface()
+		return
+	}
+	app.currentView = urgent.View
+	if urgent.Mountpoint != "" {
+		for i, disk := range app.visibleDisks(stats.Disk) {
+			if disk.Mountpoint == urgent.Mountpoint {
+				app.diskSelected, app.mountUsers = i, nil
+			}
+		}
+	}
+	severity, _ := urgent.Severity()
+	app.statusMessage = fmt.Sprintf("%s: %s", severity, urgent.Reason)
+	app.displayInterface()
+}
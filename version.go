@@ -0,0 +1,29 @@
+// version.go - Build version information
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Version is the release shown in the title bar, -version and exports.
+// Commit and BuildDate are stamped in at build time:
+//
+//	go build -ldflags "-X main.Commit=$(git rev-parse --short HEAD) -X main.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "1.0"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// SchemaVersion is written as "schema_version" in JSON exports, log
+// entries, -stream/-once output and -record files. Bump it whenever the
+// shape of that JSON changes: a field is renamed, removed, or changes type
+// or meaning. Added fields alone do not need a bump.
+const SchemaVersion = 1
+
+// printVersion writes the -version output
+func printVersion() {
+	fmt.Printf("sysmon %s (commit %s, built %s, %s %s/%s)\n",
+		Version, Commit, BuildDate, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}